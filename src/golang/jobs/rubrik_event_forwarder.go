@@ -0,0 +1,225 @@
+package jobs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// eventRecord is the structured log record forwarded for every new event.
+type eventRecord struct {
+	Timestamp     time.Time `json:"timestamp"`
+	Cluster       string    `json:"cluster"`
+	EventID       string    `json:"eventId"`
+	EventSeriesID string    `json:"eventSeriesId"`
+	EventType     string    `json:"eventType"`
+	Status        string    `json:"status"`
+	Severity      string    `json:"severity"`
+	ObjectID      string    `json:"objectId"`
+	ObjectName    string    `json:"objectName"`
+	ObjectType    string    `json:"objectType"`
+	Location      string    `json:"location"`
+	Message       string    `json:"message"`
+}
+
+// eventSink receives the events found by TailEvents.
+type eventSink interface {
+	send(records []eventRecord) error
+}
+
+// maxUnsentEvents is how many events are kept for each sink to retry after a failed
+// send. The oldest are dropped beyond that.
+const maxUnsentEvents = 10000
+
+// eventSinks holds the sinks enabled by EnableLokiForwarding and EnableFileForwarding.
+var eventSinks []eventSink
+
+// unsentEvents holds the records each sink failed to send, to retry with the next events.
+var unsentEvents = map[eventSink][]eventRecord{}
+
+// EnableLokiForwarding forwards every new event to the Loki push API at pushURL,
+// for example http://loki:3100/loki/api/v1/push.
+func EnableLokiForwarding(pushURL string) {
+	eventSinks = append(eventSinks, &lokiSink{
+		pushURL: pushURL,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	})
+}
+
+// EnableFileForwarding appends every new event as a line of JSON to path. The file is
+// rotated once it would grow past maxBytes, keeping maxBackups rotated files.
+func EnableFileForwarding(path string, maxBytes int64, maxBackups int) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	file.Close()
+	eventSinks = append(eventSinks, &fileSink{
+		path:       path,
+		maxBytes:   maxBytes,
+		maxBackups: maxBackups,
+	})
+	return nil
+}
+
+// forwardEvents sends events to every enabled sink, along with the events the sink
+// failed to send before.
+func forwardEvents(clusterName string, events []jobEvent) []error {
+	if len(eventSinks) == 0 {
+		return nil
+	}
+	records := make([]eventRecord, 0, len(events))
+	for _, event := range events {
+		records = append(records, eventRecord{
+			Timestamp:     event.time,
+			Cluster:       clusterName,
+			EventID:       event.eventID,
+			EventSeriesID: event.eventSeriesID,
+			EventType:     event.eventType,
+			Status:        event.status,
+			Severity:      event.severity,
+			ObjectID:      event.objectID,
+			ObjectName:    event.objectName,
+			ObjectType:    event.objectType,
+			Location:      event.location,
+			Message:       event.message,
+		})
+	}
+	var errs []error
+	for _, sink := range eventSinks {
+		batch := append(append([]eventRecord{}, unsentEvents[sink]...), records...)
+		if len(batch) == 0 {
+			continue
+		}
+		sort.SliceStable(batch, func(i, j int) bool {
+			return batch[i].Timestamp.Before(batch[j].Timestamp)
+		})
+		if err := sink.send(batch); err != nil {
+			if len(batch) > maxUnsentEvents {
+				batch = batch[len(batch)-maxUnsentEvents:]
+			}
+			unsentEvents[sink] = batch
+			errs = append(errs, fmt.Errorf("%v (%d events kept to retry)", err, len(batch)))
+			continue
+		}
+		delete(unsentEvents, sink)
+	}
+	return errs
+}
+
+// lokiSink pushes events to Loki, with one stream per cluster, event type and status.
+type lokiSink struct {
+	pushURL string
+	client  *http.Client
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+func (s *lokiSink) send(records []eventRecord) error {
+	streams := map[string]*lokiStream{}
+	var streamOrder []string
+	for _, record := range records {
+		line, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		key := record.Cluster + "|" + record.EventType + "|" + record.Status
+		stream, ok := streams[key]
+		if !ok {
+			stream = &lokiStream{
+				Stream: map[string]string{
+					"job":       "rubrik",
+					"cluster":   record.Cluster,
+					"eventType": record.EventType,
+					"status":    record.Status,
+				},
+			}
+			streams[key] = stream
+			streamOrder = append(streamOrder, key)
+		}
+		timestamp := record.Timestamp
+		if timestamp.IsZero() {
+			timestamp = time.Now()
+		}
+		stream.Values = append(stream.Values, [2]string{strconv.FormatInt(timestamp.UnixNano(), 10), string(line)})
+	}
+	push := struct {
+		Streams []*lokiStream `json:"streams"`
+	}{}
+	for _, key := range streamOrder {
+		push.Streams = append(push.Streams, streams[key])
+	}
+	body, err := json.Marshal(push)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Post(s.pushURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("loki push to %s returned %s", s.pushURL, resp.Status)
+	}
+	return nil
+}
+
+// fileSink writes events as newline delimited JSON, rotating path to path.1, path.2
+// and so on when it grows past maxBytes.
+type fileSink struct {
+	mu         sync.Mutex
+	path       string
+	maxBytes   int64
+	maxBackups int
+}
+
+func (s *fileSink) send(records []eventRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var lines bytes.Buffer
+	encoder := json.NewEncoder(&lines)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	if info, err := os.Stat(s.path); err == nil && s.maxBytes > 0 && info.Size()+int64(lines.Len()) > s.maxBytes {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(lines.Bytes()); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (s *fileSink) rotate() error {
+	if s.maxBackups < 1 {
+		return os.Truncate(s.path, 0)
+	}
+	os.Remove(s.path + "." + strconv.Itoa(s.maxBackups))
+	for i := s.maxBackups - 1; i >= 1; i-- {
+		oldPath := s.path + "." + strconv.Itoa(i)
+		if _, err := os.Stat(oldPath); err == nil {
+			if err := os.Rename(oldPath, s.path+"."+strconv.Itoa(i+1)); err != nil {
+				return err
+			}
+		}
+	}
+	return os.Rename(s.path, s.path+".1")
+}
@@ -0,0 +1,98 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rubrikinc/rubrik-sdk-for-go/rubrikcdm"
+)
+
+// eventStreamOverlap is how far before the previous poll the next poll starts, so that
+// events indexed late by the cluster are not missed.
+const eventStreamOverlap = 5 * time.Minute
+
+var (
+	// event stream counters
+	rubrikEvents = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rubrik_events_total",
+			Help: "Number of events emitted by Rubrik cluster.",
+		},
+		[]string{
+			"clusterName",
+			"eventType",
+			"status",
+			"objectType",
+			"severity",
+		},
+	)
+)
+
+// event stream state
+var (
+	lastEventPoll time.Time
+	seenEvents    = map[string]time.Time{}
+)
+
+func init() {
+	// event stream counters
+	prometheus.MustRegister(rubrikEvents)
+}
+
+// TailEvents ...
+func TailEvents(rubrik *rubrikcdm.Credentials, clusterName string) {
+	pre52, err := clusterIsPre52(rubrik)
+	if err != nil {
+		log.Println("Error from jobs.TailEvents: ", err)
+		return
+	}
+	pollTime := time.Now()
+	afterDate := pollTime.Add(-eventStreamOverlap)
+	if !lastEventPoll.IsZero() {
+		afterDate = lastEventPoll.Add(-eventStreamOverlap)
+	}
+	events, err := getLatestEvents(rubrik, pre52, "", "", "", afterDate)
+	if err != nil {
+		log.Println("Error from jobs.TailEvents: ", err)
+		return
+	}
+	var newEvents []jobEvent
+	for _, event := range events {
+		if _, ok := seenEvents[event.eventID]; ok {
+			continue
+		}
+		seenEvents[event.eventID] = pollTime
+		// the first poll only records what has already happened
+		if lastEventPoll.IsZero() {
+			continue
+		}
+		rubrikEvents.WithLabelValues(
+			clusterName,
+			event.eventType,
+			event.status,
+			event.objectType,
+			event.severity).Inc()
+		newEvents = append(newEvents, event)
+	}
+	// the internal event series list has no message, so read it from the series details
+	if pre52 && len(eventSinks) > 0 {
+		for i := range newEvents {
+			eventSeries, err := getEventSeries(rubrik, pre52, newEvents[i].eventSeriesID)
+			if err != nil {
+				log.Println("Error from jobs.TailEvents: ", err)
+				continue
+			}
+			newEvents[i].message = eventSeriesMessage(eventSeries, newEvents[i].status)
+		}
+	}
+	for _, err := range forwardEvents(clusterName, newEvents) {
+		log.Println("Error from jobs.TailEvents: ", err)
+	}
+	lastEventPoll = pollTime
+	for k, seen := range seenEvents {
+		if pollTime.Sub(seen) > 2*eventStreamOverlap {
+			delete(seenEvents, k)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package jobs
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rubrikinc/rubrik-client-for-prometheus/src/golang/util"
+	"github.com/rubrikinc/rubrik-sdk-for-go/rubrikcdm"
+)
+
+// jobEvent is the latest event of a Rubrik event series, flattened from either the
+// v1 event API (5.2 and newer) or the internal event series API (older clusters).
+type jobEvent struct {
+	eventID       string
+	eventSeriesID string
+	jobInstanceID string
+	eventType     string
+	status        string
+	severity      string
+	objectID      string
+	objectName    string
+	objectType    string
+	location      string
+	message       string
+	time          time.Time
+	startTime     time.Time
+	endTime       time.Time
+}
+
+// clusterIsPre52 returns true when the cluster version is older than 5.2, in which
+// case the event API is only available as the internal event series API.
+func clusterIsPre52(rubrik *rubrikcdm.Credentials) (bool, error) {
+	clusterVersion, err := rubrik.ClusterVersion()
+	if err != nil {
+		return false, err
+	}
+	clusterMajorVersion, err := strconv.ParseInt(strings.Split(clusterVersion, ".")[0], 10, 64)
+	if err != nil {
+		return false, err
+	}
+	clusterMinorVersion, err := strconv.ParseInt(strings.Split(clusterVersion, ".")[1], 10, 64)
+	if err != nil {
+		return false, err
+	}
+	return (clusterMajorVersion == 5 && clusterMinorVersion < 2) || clusterMajorVersion < 5, nil
+}
+
+// getLatestEvents returns the latest event of every event series matching the given
+// status, event type and object type, optionally limited to events after afterDate.
+// Empty filters are not sent to the cluster.
+func getLatestEvents(rubrik *rubrikcdm.Credentials, pre52 bool, status, eventType, objectType string, afterDate time.Time) ([]jobEvent, error) {
+	query := url.Values{}
+	if pre52 {
+		if status != "" {
+			query.Set("status", status)
+		}
+	} else {
+		query.Set("limit", "9999")
+		if status != "" {
+			query.Set("event_status", status)
+		}
+		if !afterDate.IsZero() {
+			query.Set("after_date", afterDate.UTC().Format("2006-01-02T15:04:05.000Z"))
+		}
+	}
+	if eventType != "" {
+		query.Set("event_type", eventType)
+	}
+	if objectType != "" {
+		query.Set("object_type", objectType)
+	}
+	var events []jobEvent
+	if pre52 {
+		// the internal API has no date filter, so page through the event series until a
+		// page holds nothing after afterDate, as they are listed newest first
+		query.Set("limit", "500")
+		offset := 0
+		for {
+			query.Set("offset", strconv.Itoa(offset))
+			eventData, err := rubrik.Get("internal", "/event_series?"+query.Encode(), 60)
+			if err != nil {
+				return nil, err
+			}
+			data, _ := eventData.(map[string]interface{})["data"].([]interface{})
+			pageEvents := parseEventSeries(data, afterDate)
+			events = append(events, pageEvents...)
+			offset += len(data)
+			hasMore, _ := eventData.(map[string]interface{})["hasMore"].(bool)
+			if !hasMore || len(data) == 0 || (!afterDate.IsZero() && len(pageEvents) == 0) {
+				return events, nil
+			}
+		}
+	}
+	eventData, err := rubrik.Get("v1", "/event/latest?"+query.Encode(), 60)
+	if err != nil {
+		return nil, err
+	}
+	data, _ := eventData.(map[string]interface{})["data"].([]interface{})
+	for _, v := range data {
+		latestEvent, ok := v.(map[string]interface{})["latestEvent"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		event := jobEvent{
+			eventID:       util.ObjectString(latestEvent, "id"),
+			eventSeriesID: util.ObjectString(latestEvent, "eventSeriesId"),
+			jobInstanceID: util.ObjectString(latestEvent, "jobInstanceId"),
+			eventType:     util.ObjectString(latestEvent, "eventType"),
+			status:        util.ObjectString(latestEvent, "eventStatus"),
+			severity:      util.ObjectString(latestEvent, "eventSeverity", "severity"),
+			objectID:      util.ObjectString(latestEvent, "objectId"),
+			objectName:    util.ObjectString(latestEvent, "objectName"),
+			objectType:    util.ObjectString(latestEvent, "objectType"),
+			location:      util.ObjectString(v.(map[string]interface{}), "location"),
+			time:          eventTime(latestEvent, "time"),
+		}
+		event.message = eventMessage(latestEvent)
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// parseEventSeries flattens a page of the internal event series API, skipping events
+// before afterDate.
+func parseEventSeries(data []interface{}, afterDate time.Time) []jobEvent {
+	var events []jobEvent
+	for _, v := range data {
+		thisEvent, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		objectInfo, _ := thisEvent["objectInfo"].(map[string]interface{})
+		event := jobEvent{
+			eventSeriesID: util.ObjectString(thisEvent, "eventSeriesId"),
+			jobInstanceID: util.ObjectString(thisEvent, "jobInstanceId"),
+			eventType:     util.ObjectString(thisEvent, "eventType"),
+			status:        util.ObjectString(thisEvent, "status"),
+			severity:      util.ObjectString(thisEvent, "eventSeverity", "severity"),
+			objectID:      util.ObjectString(objectInfo, "objectId"),
+			objectName:    util.ObjectString(objectInfo, "objectName"),
+			objectType:    util.ObjectString(objectInfo, "objectType"),
+			location:      util.ObjectString(thisEvent, "location"),
+			time:          eventTime(thisEvent, "eventDate"),
+			startTime:     eventTime(thisEvent, "startTime"),
+			endTime:       eventTime(thisEvent, "endTime"),
+		}
+		// the internal API has no event IDs, but a series only reports one event per status
+		event.eventID = event.eventSeriesID + ":" + event.status
+		if !afterDate.IsZero() && event.time.Before(afterDate) {
+			continue
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// getEventSeries returns the details of an event series, from the internal event series
+// API on clusters older than 5.2.
+func getEventSeries(rubrik *rubrikcdm.Credentials, pre52 bool, eventSeriesID string) (map[string]interface{}, error) {
+	apiVersion := "v1"
+	if pre52 {
+		apiVersion = "internal"
+	}
+	eventSeriesData, err := rubrik.Get(apiVersion, "/event_series/"+eventSeriesID, 60)
+	if err != nil {
+		return nil, err
+	}
+	eventSeries, _ := eventSeriesData.(map[string]interface{})
+	return eventSeries, nil
+}
+
+// getEventSeriesTimes returns the start and end time of an event series, which the v1
+// latest event API does not include. The end time is zero while the series is running.
+func getEventSeriesTimes(rubrik *rubrikcdm.Credentials, pre52 bool, eventSeriesID string) (time.Time, time.Time, error) {
+	eventSeries, err := getEventSeries(rubrik, pre52, eventSeriesID)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return eventTime(eventSeries, "startTime"), eventTime(eventSeries, "endTime"), nil
+}
+
+// eventSeriesMessage returns the message of the latest event of an event series with
+// the given status, or of the latest event when none has it.
+func eventSeriesMessage(eventSeries map[string]interface{}, status string) string {
+	var latest, latestWithStatus map[string]interface{}
+	eventDetails, _ := eventSeries["eventDetailList"].([]interface{})
+	for _, v := range eventDetails {
+		eventDetail, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if latest == nil || eventTime(eventDetail, "time").After(eventTime(latest, "time")) {
+			latest = eventDetail
+		}
+		if util.ObjectString(eventDetail, "eventStatus", "status") == status &&
+			(latestWithStatus == nil || eventTime(eventDetail, "time").After(eventTime(latestWithStatus, "time"))) {
+			latestWithStatus = eventDetail
+		}
+	}
+	if latestWithStatus != nil {
+		return eventMessage(latestWithStatus)
+	}
+	return eventMessage(latest)
+}
+
+// eventMessage returns the message in the JSON eventInfo of an event, or "" when it
+// has none.
+func eventMessage(event map[string]interface{}) string {
+	eventInfo, ok := event["eventInfo"].(string)
+	if !ok {
+		return ""
+	}
+	var info map[string]interface{}
+	if json.Unmarshal([]byte(eventInfo), &info) != nil {
+		return ""
+	}
+	message, _ := info["message"].(string)
+	return message
+}
+
+// eventTime returns the time value of key, or the zero time when it is missing.
+func eventTime(data map[string]interface{}, key string) time.Time {
+	value, ok := data[key].(string)
+	if !ok {
+		return time.Time{}
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return parsed
+}
@@ -3,8 +3,8 @@ package jobs
 import (
 	"log"
 	"strconv"
-	"strings"
 	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rubrikinc/rubrik-sdk-for-go/rubrikcdm"
 )
@@ -46,53 +46,75 @@ var (
 			"eventDate",
 		},
 	)
+	// failed job counts
+	rubrikFailedJobs = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rubrik_failed_jobs_total",
+			Help: "Number of failed Rubrik Backup jobs.",
+		},
+		[]string{
+			"clusterName",
+			"objectType",
+		},
+	)
+)
+
+// job counter state by object type, only used by the failed job collectors
+var (
+	lastJobCount     = map[string]time.Time{}
+	countedJobSeries = map[string]map[string]time.Time{}
 )
 
 func init() {
 	// failed job details
 	prometheus.MustRegister(rubrikMssqlFailedJob)
 	prometheus.MustRegister(rubrikVmwareVmFailedJob)
+	// failed job counts
+	prometheus.MustRegister(rubrikFailedJobs)
 }
 
 // GetMssqlFailedJobs ...
 func GetMssqlFailedJobs(rubrik *rubrikcdm.Credentials, clusterName string) {
-	clusterVersion, err := rubrik.ClusterVersion()
-	if err != nil {
-		log.Printf("Error from jobs.GetMssqlFailedJobs: ", err)
-		return
-	}
-	clusterMajorVersion, err := strconv.ParseInt(strings.Split(clusterVersion, ".")[0], 10, 64)
-	if err != nil {
-		log.Printf("Error from jobs.GetMssqlFailedJobs: ", err)
-		return
-	}
-	clusterMinorVersion, err := strconv.ParseInt(strings.Split(clusterVersion, ".")[1], 10, 64)
+	getStatusJobs(rubrik, clusterName, "Mssql", "Failure", rubrikMssqlFailedJob, rubrikFailedJobs, "jobs.GetMssqlFailedJobs")
+}
+
+// GetVmwareVmFailedJobs ...
+func GetVmwareVmFailedJobs(rubrik *rubrikcdm.Credentials, clusterName string) {
+	getStatusJobs(rubrik, clusterName, "VmwareVm", "Failure", rubrikVmwareVmFailedJob, rubrikFailedJobs, "jobs.GetVmwareVmFailedJobs")
+}
+
+// getStatusJobs sets jobGauge for every backup job of objectType with an event of the
+// given status, such as Failure or Warning. When jobCounter is not nil it is incremented
+// once per new job, with the event series and object as an exemplar. caller is used to
+// prefix logged errors.
+func getStatusJobs(rubrik *rubrikcdm.Credentials, clusterName, objectType, status string, jobGauge *prometheus.GaugeVec, jobCounter *prometheus.CounterVec, caller string) {
+	pre52, err := clusterIsPre52(rubrik)
 	if err != nil {
-		log.Printf("Error from jobs.GetMssqlFailedJobs: ", err)
+		log.Println("Error from "+caller+": ", err)
 		return
 	}
-	if (clusterMajorVersion == 5 && clusterMinorVersion < 2) || clusterMajorVersion < 5 { // cluster version is older than 5.1
-		eventData, err := rubrik.Get("internal", "/event_series?status=Failure&event_type=Backup&object_type=Mssql", 60)
+	if pre52 { // cluster version is older than 5.2
+		eventData, err := rubrik.Get("internal", "/event_series?status="+status+"&event_type=Backup&object_type="+objectType, 60)
 		if err != nil {
-			log.Printf("Error from jobs.GetMssqlFailedJobs: ", err)
+			log.Println("Error from "+caller+": ", err)
 			return
 		}
-		if eventData != nil || eventData.(map[string]interface{})["data"] != nil {
+		if eventData != nil && eventData.(map[string]interface{})["data"] != nil {
 			for _, v := range eventData.(map[string]interface{})["data"].([]interface{}) {
 				thisEventSeriesID := v.(map[string]interface{})["eventSeriesId"]
 				eventSeriesData, err := rubrik.Get("internal", "/event_series/"+thisEventSeriesID.(string), 60)
 				if err != nil {
-					log.Printf("Error from jobs.GetMssqlFailedJobs: ", err)
+					log.Println("Error from "+caller+": ", err)
 					return
 				}
-				hasFailedEvent := false
+				hasStatusEvent := false
 				for _, w := range eventSeriesData.(map[string]interface{})["eventDetailList"].([]interface{}) {
 					thisEventStatus := w.(map[string]interface{})["status"]
-					if thisEventStatus == "Failure" {
-						hasFailedEvent = true
+					if thisEventStatus == status {
+						hasStatusEvent = true
 					}
 				}
-				if hasFailedEvent == true {
+				if hasStatusEvent == true {
 					thisObjectName := v.(map[string]interface{})["objectInfo"].(map[string]interface{})["objectName"]
 					thisObjectID := v.(map[string]interface{})["objectInfo"].(map[string]interface{})["objectId"]
 					thisLocation := v.(map[string]interface{})["location"]
@@ -121,7 +143,7 @@ func GetMssqlFailedJobs(rubrik *rubrikcdm.Credentials, clusterName string) {
 						thisDuration = v.(map[string]interface{})["duration"].(string)
 					}
 					thisEventDate := v.(map[string]interface{})["eventDate"]
-					rubrikMssqlFailedJob.WithLabelValues(
+					jobGauge.WithLabelValues(
 						clusterName,
 						thisObjectName.(string),
 						thisObjectID.(string),
@@ -136,27 +158,27 @@ func GetMssqlFailedJobs(rubrik *rubrikcdm.Credentials, clusterName string) {
 		}
 	} else { // cluster version is 5.2 or newer
 		var yesterday = time.Now().AddDate(0, 0, -1).Format("2006-01-02T15:04:05.000Z")
-		eventData, err := rubrik.Get("v1", "/event/latest?limit=9999&event_status=Failure&event_type=Backup&object_type=Mssql&before_date="+yesterday, 60)
+		eventData, err := rubrik.Get("v1", "/event/latest?limit=9999&event_status="+status+"&event_type=Backup&object_type="+objectType+"&before_date="+yesterday, 60)
 		if err != nil {
-			log.Printf("Error from jobs.GetMssqlFailedJobs: ", err)
+			log.Println("Error from "+caller+": ", err)
 			return
 		}
-		if eventData != nil || eventData.(map[string]interface{})["data"] != nil {
+		if eventData != nil && eventData.(map[string]interface{})["data"] != nil {
 			for _, v := range eventData.(map[string]interface{})["data"].([]interface{}) {
 				thisEventSeriesID := v.(map[string]interface{})["latestEvent"].(map[string]interface{})["eventSeriesId"]
 				eventSeriesData, err := rubrik.Get("v1", "/event_series/"+thisEventSeriesID.(string), 60)
 				if err != nil {
-					log.Printf("Error from jobs.GetMssqlFailedJobs: ", err)
+					log.Println("Error from "+caller+": ", err)
 					return
 				}
-				hasFailedEvent := false
+				hasStatusEvent := false
 				for _, w := range eventSeriesData.(map[string]interface{})["eventDetailList"].([]interface{}) {
 					thisEventStatus := w.(map[string]interface{})["eventStatus"]
-					if thisEventStatus == "Failure" {
-						hasFailedEvent = true
+					if thisEventStatus == status {
+						hasStatusEvent = true
 					}
 				}
-				if hasFailedEvent == true {
+				if hasStatusEvent == true {
 					thisObjectName := eventSeriesData.(map[string]interface{})["objectName"]
 					thisObjectID := eventSeriesData.(map[string]interface{})["objectId"]
 					thisLocation := eventSeriesData.(map[string]interface{})["location"]
@@ -185,7 +207,7 @@ func GetMssqlFailedJobs(rubrik *rubrikcdm.Credentials, clusterName string) {
 						thisDuration = eventSeriesData.(map[string]interface{})["duration"].(string)
 					}
 					thisEventDate := eventSeriesData.(map[string]interface{})["startTime"]
-					rubrikMssqlFailedJob.WithLabelValues(
+					jobGauge.WithLabelValues(
 						clusterName,
 						thisObjectName.(string),
 						thisObjectID.(string),
@@ -199,150 +221,58 @@ func GetMssqlFailedJobs(rubrik *rubrikcdm.Credentials, clusterName string) {
 			}
 		}
 	}
+	if jobCounter != nil {
+		countStatusJobs(rubrik, pre52, clusterName, objectType, status, jobCounter, caller)
+	}
 }
-// GetVmwareVMFailedJobs ...
-func GetVmwareVmFailedJobs(rubrik *rubrikcdm.Credentials, clusterName string) {
-	clusterVersion, err := rubrik.ClusterVersion()
-	if err != nil {
-		log.Printf("Error from jobs.GetVmwareVmFailedJobs: ", err)
-		return
+
+// countStatusJobs increments jobCounter for every event series of objectType whose
+// latest event has the given status and happened since the previous call.
+func countStatusJobs(rubrik *rubrikcdm.Credentials, pre52 bool, clusterName, objectType, status string, jobCounter *prometheus.CounterVec, caller string) {
+	pollTime := time.Now()
+	lastPoll, polled := lastJobCount[objectType]
+	afterDate := pollTime.Add(-eventStreamOverlap)
+	if polled {
+		afterDate = lastPoll.Add(-eventStreamOverlap)
 	}
-	clusterMajorVersion, err := strconv.ParseInt(strings.Split(clusterVersion, ".")[0], 10, 64)
+	events, err := getLatestEvents(rubrik, pre52, status, "Backup", objectType, afterDate)
 	if err != nil {
-		log.Printf("Error from jobs.GetVmwareVmFailedJobs: ", err)
+		log.Println("Error from "+caller+": ", err)
 		return
 	}
-	clusterMinorVersion, err := strconv.ParseInt(strings.Split(clusterVersion, ".")[1], 10, 64)
-	if err != nil {
-		log.Printf("Error from jobs.GetVmwareVmFailedJobs: ", err)
-		return
+	counted, ok := countedJobSeries[objectType]
+	if !ok {
+		counted = map[string]time.Time{}
+		countedJobSeries[objectType] = counted
 	}
-	if (clusterMajorVersion == 5 && clusterMinorVersion < 2) || clusterMajorVersion < 5 { // cluster version is older than 5.1
-		eventData, err := rubrik.Get("internal", "/event_series?status=Failure&event_type=Backup&object_type=VmwareVm", 60)
-		if err != nil {
-			log.Printf("Error from jobs.GetVmwareVmFailedJobs: ", err)
-			return
+	for _, event := range events {
+		if _, ok := counted[event.eventSeriesID]; ok {
+			continue
 		}
-		if eventData != nil || eventData.(map[string]interface{})["data"] != nil {
-			for _, v := range eventData.(map[string]interface{})["data"].([]interface{}) {
-				thisEventSeriesID := v.(map[string]interface{})["eventSeriesId"]
-				eventSeriesData, err := rubrik.Get("internal", "/event_series/"+thisEventSeriesID.(string), 60)
-				if err != nil {
-					log.Printf("Error from jobs.GetVmwareVmFailedJobs: ", err)
-					return
-				}
-				hasFailedEvent := false
-				for _, w := range eventSeriesData.(map[string]interface{})["eventDetailList"].([]interface{}) {
-					thisEventStatus := w.(map[string]interface{})["status"]
-					if thisEventStatus == "Failure" {
-						hasFailedEvent = true
-					}
-				}
-				if hasFailedEvent == true {
-					thisObjectName := v.(map[string]interface{})["objectInfo"].(map[string]interface{})["objectName"]
-					thisObjectID := v.(map[string]interface{})["objectInfo"].(map[string]interface{})["objectId"]
-					thisLocation := v.(map[string]interface{})["location"]
-					var thisStartTime string
-					if v.(map[string]interface{})["startTime"] == nil {
-						thisStartTime = "null"
-					} else {
-						thisStartTime = v.(map[string]interface{})["startTime"].(string)
-					}
-					var thisEndTime string
-					if v.(map[string]interface{})["endTime"] == nil {
-						thisEndTime = "null"
-					} else {
-						thisEndTime = v.(map[string]interface{})["endTime"].(string)
-					}
-					var thisLogicalSize string
-					if v.(map[string]interface{})["objectLogicalSize"] == nil {
-						thisLogicalSize = "null"
-					} else {
-						thisLogicalSize = strconv.FormatFloat(v.(map[string]interface{})["objectLogicalSize"].(float64), 'f', -1, 64)
-					}
-					var thisDuration string
-					if v.(map[string]interface{})["duration"] == nil {
-						thisDuration = "null"
-					} else {
-						thisDuration = v.(map[string]interface{})["duration"].(string)
-					}
-					thisEventDate := v.(map[string]interface{})["eventDate"]
-					rubrikVmwareVmFailedJob.WithLabelValues(
-						clusterName,
-						thisObjectName.(string),
-						thisObjectID.(string),
-						thisLocation.(string),
-						thisStartTime,
-						thisEndTime,
-						thisLogicalSize,
-						thisDuration,
-						thisEventDate.(string)).Set(1)
-				}
-			}
+		eventTime := event.time
+		if eventTime.IsZero() {
+			eventTime = pollTime
 		}
-	} else { // cluster version is 5.2 or newer
-		var yesterday = time.Now().AddDate(0, 0, -1).Format("2006-01-02T15:04:05.000Z")
-		eventData, err := rubrik.Get("v1", "/event/latest?limit=9999&event_status=Failure&event_type=Backup&object_type=VmwareVm&before_date="+yesterday, 60)
-		if err != nil {
-			log.Printf("Error from jobs.GetVmwareVmFailedJobs: ", err)
-			return
+		counted[event.eventSeriesID] = eventTime
+		// the first poll only records the jobs that have already happened
+		if !polled {
+			continue
 		}
-		if eventData != nil || eventData.(map[string]interface{})["data"] != nil {
-			for _, v := range eventData.(map[string]interface{})["data"].([]interface{}) {
-				thisEventSeriesID := v.(map[string]interface{})["latestEvent"].(map[string]interface{})["eventSeriesId"]
-				eventSeriesData, err := rubrik.Get("v1", "/event_series/"+thisEventSeriesID.(string), 60)
-				if err != nil {
-					log.Printf("Error from jobs.GetVmwareVmFailedJobs: ", err)
-					return
-				}
-				hasFailedEvent := false
-				for _, w := range eventSeriesData.(map[string]interface{})["eventDetailList"].([]interface{}) {
-					thisEventStatus := w.(map[string]interface{})["eventStatus"]
-					if thisEventStatus == "Failure" {
-						hasFailedEvent = true
-					}
-				}
-				if hasFailedEvent == true {
-					thisObjectName := eventSeriesData.(map[string]interface{})["objectName"]
-					thisObjectID := eventSeriesData.(map[string]interface{})["objectId"]
-					thisLocation := eventSeriesData.(map[string]interface{})["location"]
-					var thisStartTime string
-					if eventSeriesData.(map[string]interface{})["startTime"] == nil {
-						thisStartTime = "null"
-					} else {
-						thisStartTime = eventSeriesData.(map[string]interface{})["startTime"].(string)
-					}
-					var thisEndTime string
-					if eventSeriesData.(map[string]interface{})["endTime"] == nil {
-						thisEndTime = "null"
-					} else {
-						thisEndTime = eventSeriesData.(map[string]interface{})["endTime"].(string)
-					}
-					var thisLogicalSize string
-					if eventSeriesData.(map[string]interface{})["logicalSize"] == nil {
-						thisLogicalSize = "null"
-					} else {
-						thisLogicalSize = strconv.FormatFloat(eventSeriesData.(map[string]interface{})["logicalSize"].(float64), 'f', -1, 64)
-					}
-					var thisDuration string
-					if eventSeriesData.(map[string]interface{})["duration"] == nil {
-						thisDuration = "null"
-					} else {
-						thisDuration = eventSeriesData.(map[string]interface{})["duration"].(string)
-					}
-					thisEventDate := eventSeriesData.(map[string]interface{})["startTime"]
-					rubrikVmwareVmFailedJob.WithLabelValues(
-							clusterName,
-							thisObjectName.(string),
-							thisObjectID.(string),
-							thisLocation.(string),
-							thisStartTime,
-							thisEndTime,
-							thisLogicalSize,
-							thisDuration,
-							thisEventDate.(string)).Set(1)
-				}
-			}
+		counter := jobCounter.WithLabelValues(clusterName, objectType)
+		if exemplarAdder, ok := counter.(prometheus.ExemplarAdder); ok {
+			exemplarAdder.AddWithExemplar(1, prometheus.Labels{
+				"eventSeriesId": event.eventSeriesID,
+				"objectId":      event.objectID,
+			})
+			continue
+		}
+		counter.Inc()
+	}
+	lastJobCount[objectType] = pollTime
+	// forget the event series that the next poll can no longer return
+	for k, eventTime := range counted {
+		if eventTime.Before(pollTime.Add(-eventStreamOverlap)) {
+			delete(counted, k)
 		}
 	}
 }
@@ -0,0 +1,239 @@
+package jobs
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rubrikinc/rubrik-client-for-prometheus/src/golang/util"
+	"github.com/rubrikinc/rubrik-sdk-for-go/rubrikcdm"
+)
+
+// recoveryJobWindow is how far back GetRecoveryJobs looks, set by SetRecoveryJobWindow.
+var recoveryJobWindow = 24 * time.Hour
+
+// completedJobStatuses are the event statuses which end an event series.
+var completedJobStatuses = map[string]bool{
+	"Success":     true,
+	"Failure":     true,
+	"Canceled":    true,
+	"Warning":     true,
+	"TaskSuccess": true,
+	"TaskFailure": true,
+}
+
+var (
+	// recovery job details
+	rubrikRecoveryJobs = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_recovery_jobs",
+			Help: "Number of recovery jobs in Rubrik cluster within the recovery job window.",
+		},
+		[]string{
+			"clusterName",
+			"objectType",
+			"recoveryType",
+			"status",
+		},
+	)
+	rubrikRecoveryJobMaxDuration = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_recovery_job_max_duration_seconds",
+			Help: "Duration of the longest completed recovery job in Rubrik cluster within the recovery job window.",
+		},
+		[]string{
+			"clusterName",
+			"objectType",
+			"recoveryType",
+			"status",
+		},
+	)
+	rubrikRecoveryJobDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "rubrik_recovery_job_duration_seconds",
+			Help:    "Duration of completed Rubrik recovery jobs.",
+			Buckets: prometheus.ExponentialBuckets(30, 2, 12),
+		},
+		[]string{
+			"clusterName",
+			"objectType",
+			"recoveryType",
+			"status",
+		},
+	)
+)
+
+// recoveryJobPrefixes map the job instance ID prefixes of recovery jobs onto the
+// recovery types reported. Job instance IDs are the job type followed by the object
+// and job IDs, such as RESTORE_MSSQL_DB_<id>.
+var recoveryJobPrefixes = []struct {
+	prefix       string
+	recoveryType string
+}{
+	{"EXPORT_", "Export"},
+	{"INSTANT_RECOVER", "InstantRecovery"},
+	{"MOUNT_", "LiveMount"},
+	{"CREATE_MOUNT_", "LiveMount"},
+	{"RESTORE_FILE", "FileRestore"},
+	{"DOWNLOAD_FILE", "FileRestore"},
+	{"RESTORE_MSSQL_DB", "DatabaseRestore"},
+	{"RESTORE_ORACLE_DB", "DatabaseRestore"},
+	{"RECOVER_MSSQL_DB", "DatabaseRestore"},
+	{"RECOVER_ORACLE_DB", "DatabaseRestore"},
+}
+
+// ignoredRecoveryJobPrefixes are the job instance ID prefixes of Recovery events which
+// are not recovery jobs, such as removing a live mount.
+var ignoredRecoveryJobPrefixes = []string{
+	"UNMOUNT_",
+	"DELETE_MOUNT_",
+}
+
+// recoveryJobs caches the recovery type and duration of recovery jobs by event series,
+// so that each series is only looked up and observed once.
+var recoveryJobs = map[string]recoveryJob{}
+
+type recoveryJob struct {
+	recoveryType string
+	lookedUp     bool
+	observed     bool
+	duration     time.Duration
+	seen         time.Time
+}
+
+func init() {
+	// recovery job details
+	prometheus.MustRegister(rubrikRecoveryJobs)
+	prometheus.MustRegister(rubrikRecoveryJobMaxDuration)
+	prometheus.MustRegister(rubrikRecoveryJobDuration)
+}
+
+// recoveryJobKey identifies one series of the recovery job metrics.
+type recoveryJobKey struct {
+	objectType   string
+	recoveryType string
+	status       string
+}
+
+// SetRecoveryJobWindow sets how far back GetRecoveryJobs looks for recovery jobs.
+func SetRecoveryJobWindow(window time.Duration) {
+	recoveryJobWindow = window
+}
+
+// GetRecoveryJobs ...
+func GetRecoveryJobs(rubrik *rubrikcdm.Credentials, clusterName string) {
+	pre52, err := clusterIsPre52(rubrik)
+	if err != nil {
+		log.Println("Error from jobs.GetRecoveryJobs: ", err)
+		return
+	}
+	events, err := getLatestEvents(rubrik, pre52, "", "Recovery", "", time.Now().Add(-recoveryJobWindow))
+	if err != nil {
+		log.Println("Error from jobs.GetRecoveryJobs: ", err)
+		return
+	}
+	counts := map[recoveryJobKey]float64{}
+	maxDurations := map[recoveryJobKey]float64{}
+	for _, event := range events {
+		job, ok := recoveryJobs[event.eventSeriesID]
+		if !ok {
+			job.recoveryType = recoveryType(event.jobInstanceID)
+		}
+		job.seen = time.Now()
+		complete := completedJobStatuses[event.status]
+		startTime, endTime := event.startTime, event.endTime
+		// the latest event list does not include the job instance ID on older clusters, nor
+		// the start and end time on newer ones, so look them up in the event series
+		needsType := job.recoveryType == "Unknown" && !job.lookedUp
+		needsDuration := complete && !job.observed && (startTime.IsZero() || endTime.IsZero())
+		if needsType || needsDuration {
+			// on errors the job is counted as it is, and looked up again on the next collection
+			eventSeries, err := getEventSeries(rubrik, pre52, event.eventSeriesID)
+			if err != nil {
+				log.Println("Error from jobs.GetRecoveryJobs: ", err)
+			} else {
+				job.lookedUp = true
+				if needsType {
+					job.recoveryType = recoveryType(eventSeriesJobInstanceID(eventSeries))
+				}
+				startTime, endTime = eventTime(eventSeries, "startTime"), eventTime(eventSeries, "endTime")
+			}
+		}
+		recoveryJobs[event.eventSeriesID] = job
+		if job.recoveryType == "" {
+			continue
+		}
+		thisJob := recoveryJobKey{event.objectType, job.recoveryType, event.status}
+		counts[thisJob]++
+		if !complete {
+			continue
+		}
+		if !job.observed {
+			if startTime.IsZero() || endTime.IsZero() {
+				continue
+			}
+			job.observed = true
+			job.duration = endTime.Sub(startTime)
+			recoveryJobs[event.eventSeriesID] = job
+			rubrikRecoveryJobDuration.WithLabelValues(
+				clusterName,
+				thisJob.objectType,
+				thisJob.recoveryType,
+				thisJob.status).Observe(job.duration.Seconds())
+		}
+		if job.duration.Seconds() > maxDurations[thisJob] {
+			maxDurations[thisJob] = job.duration.Seconds()
+		}
+	}
+	for k, job := range recoveryJobs {
+		if time.Since(job.seen) > 2*recoveryJobWindow {
+			delete(recoveryJobs, k)
+		}
+	}
+	// replace the previous values, so that jobs which have left the window drop out
+	rubrikRecoveryJobs.Reset()
+	rubrikRecoveryJobMaxDuration.Reset()
+	for k, count := range counts {
+		rubrikRecoveryJobs.WithLabelValues(clusterName, k.objectType, k.recoveryType, k.status).Set(count)
+	}
+	for k, maxDuration := range maxDurations {
+		rubrikRecoveryJobMaxDuration.WithLabelValues(clusterName, k.objectType, k.recoveryType, k.status).Set(maxDuration)
+	}
+}
+
+// recoveryType classifies a recovery job from the prefix of its job instance ID. It
+// returns Unknown when there is no job instance ID, Other for recovery jobs of an
+// unknown type and an empty string for Recovery events which are not recovery jobs.
+func recoveryType(jobInstanceID string) string {
+	if jobInstanceID == "" || jobInstanceID == "null" {
+		return "Unknown"
+	}
+	for _, prefix := range ignoredRecoveryJobPrefixes {
+		if strings.HasPrefix(jobInstanceID, prefix) {
+			return ""
+		}
+	}
+	for _, p := range recoveryJobPrefixes {
+		if strings.HasPrefix(jobInstanceID, p.prefix) {
+			return p.recoveryType
+		}
+	}
+	return "Other"
+}
+
+// eventSeriesJobInstanceID returns the job instance ID of an event series, which older
+// clusters only report on its events.
+func eventSeriesJobInstanceID(eventSeries map[string]interface{}) string {
+	if jobInstanceID := util.ObjectString(eventSeries, "jobInstanceId"); jobInstanceID != "null" {
+		return jobInstanceID
+	}
+	eventDetails, _ := eventSeries["eventDetailList"].([]interface{})
+	for _, v := range eventDetails {
+		eventDetail, _ := v.(map[string]interface{})
+		if jobInstanceID := util.ObjectString(eventDetail, "jobInstanceId"); jobInstanceID != "null" {
+			return jobInstanceID
+		}
+	}
+	return "null"
+}
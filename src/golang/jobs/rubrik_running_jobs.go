@@ -0,0 +1,147 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rubrikinc/rubrik-sdk-for-go/rubrikcdm"
+)
+
+// recentJobWindow is how long after starting a job is counted as recently started.
+const recentJobWindow = 15 * time.Minute
+
+var (
+	// running and queued job details
+	rubrikRunningJobs = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_running_jobs",
+			Help: "Number of jobs currently running in Rubrik cluster.",
+		},
+		[]string{
+			"clusterName",
+			"jobType",
+			"objectType",
+		},
+	)
+	rubrikQueuedJobs = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_queued_jobs",
+			Help: "Number of jobs currently queued in Rubrik cluster.",
+		},
+		[]string{
+			"clusterName",
+			"jobType",
+			"objectType",
+		},
+	)
+	rubrikRecentlyStartedJobs = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_recently_started_jobs",
+			Help: "Number of running jobs in Rubrik cluster started in the last 15 minutes.",
+		},
+		[]string{
+			"clusterName",
+			"jobType",
+			"objectType",
+		},
+	)
+	rubrikOldestRunningJobAge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_oldest_running_job_age_seconds",
+			Help: "Age of the oldest running job in Rubrik cluster.",
+		},
+		[]string{
+			"clusterName",
+			"jobType",
+			"objectType",
+		},
+	)
+)
+
+// runningJobStartTimes caches the start time of running event series, so that each is
+// only looked up once while it runs.
+var runningJobStartTimes = map[string]time.Time{}
+
+func init() {
+	// running and queued job details
+	prometheus.MustRegister(rubrikRunningJobs)
+	prometheus.MustRegister(rubrikQueuedJobs)
+	prometheus.MustRegister(rubrikRecentlyStartedJobs)
+	prometheus.MustRegister(rubrikOldestRunningJobAge)
+}
+
+// runningJobKey identifies one series of the running job metrics.
+type runningJobKey struct {
+	jobType    string
+	objectType string
+}
+
+// GetRunningJobs ...
+func GetRunningJobs(rubrik *rubrikcdm.Credentials, clusterName string) {
+	pre52, err := clusterIsPre52(rubrik)
+	if err != nil {
+		log.Println("Error from jobs.GetRunningJobs: ", err)
+		return
+	}
+	runningEvents, err := getLatestEvents(rubrik, pre52, "Running", "", "", time.Time{})
+	if err != nil {
+		log.Println("Error from jobs.GetRunningJobs: ", err)
+		return
+	}
+	queuedEvents, err := getLatestEvents(rubrik, pre52, "Queued", "", "", time.Time{})
+	if err != nil {
+		log.Println("Error from jobs.GetRunningJobs: ", err)
+		return
+	}
+	running := map[runningJobKey]float64{}
+	recentlyStarted := map[runningJobKey]float64{}
+	oldestAge := map[runningJobKey]float64{}
+	startTimes := map[string]time.Time{}
+	for _, event := range runningEvents {
+		thisJob := runningJobKey{event.eventType, event.objectType}
+		running[thisJob]++
+		startTime := event.startTime
+		if startTime.IsZero() {
+			startTime = runningJobStartTimes[event.eventSeriesID]
+		}
+		if startTime.IsZero() {
+			startTime, _, err = getEventSeriesTimes(rubrik, pre52, event.eventSeriesID)
+			if err != nil {
+				// count the job without an age, and look up its start time again next time
+				log.Println("Error from jobs.GetRunningJobs: ", err)
+				continue
+			}
+		}
+		startTimes[event.eventSeriesID] = startTime
+		if startTime.IsZero() {
+			continue
+		}
+		age := time.Since(startTime)
+		if age < recentJobWindow {
+			recentlyStarted[thisJob]++
+		}
+		if age.Seconds() > oldestAge[thisJob] {
+			oldestAge[thisJob] = age.Seconds()
+		}
+	}
+	// only keep the start times of jobs which are still running
+	runningJobStartTimes = startTimes
+	queued := map[runningJobKey]float64{}
+	for _, event := range queuedEvents {
+		queued[runningJobKey{event.eventType, event.objectType}]++
+	}
+	// replace the previous values, so that finished jobs drop out
+	rubrikRunningJobs.Reset()
+	rubrikQueuedJobs.Reset()
+	rubrikRecentlyStartedJobs.Reset()
+	rubrikOldestRunningJobAge.Reset()
+	for k, count := range running {
+		rubrikRunningJobs.WithLabelValues(clusterName, k.jobType, k.objectType).Set(count)
+		rubrikRecentlyStartedJobs.WithLabelValues(clusterName, k.jobType, k.objectType).Set(recentlyStarted[k])
+		rubrikOldestRunningJobAge.WithLabelValues(clusterName, k.jobType, k.objectType).Set(oldestAge[k])
+	}
+	for k, count := range queued {
+		rubrikQueuedJobs.WithLabelValues(clusterName, k.jobType, k.objectType).Set(count)
+	}
+}
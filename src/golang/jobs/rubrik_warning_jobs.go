@@ -0,0 +1,61 @@
+package jobs
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rubrikinc/rubrik-sdk-for-go/rubrikcdm"
+)
+
+var (
+	// Mssql warning job details
+	rubrikMssqlWarningJob = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_mssql_warning_job",
+			Help: "Information for Rubrik MSSQL Backup job which completed with warnings.",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectID",
+			"location",
+			"startTime",
+			"endTime",
+			"objectLogicalSize",
+			"duration",
+			"eventDate",
+		},
+	)
+	// VM warning job details
+	rubrikVmwareVmWarningJob = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_vmwarevm_warning_job",
+			Help: "Information for Rubrik VMware VM Backup job which completed with warnings.",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectID",
+			"location",
+			"startTime",
+			"endTime",
+			"objectLogicalSize",
+			"duration",
+			"eventDate",
+		},
+	)
+)
+
+func init() {
+	// warning job details
+	prometheus.MustRegister(rubrikMssqlWarningJob)
+	prometheus.MustRegister(rubrikVmwareVmWarningJob)
+}
+
+// GetMssqlWarningJobs ...
+func GetMssqlWarningJobs(rubrik *rubrikcdm.Credentials, clusterName string) {
+	getStatusJobs(rubrik, clusterName, "Mssql", "Warning", rubrikMssqlWarningJob, nil, "jobs.GetMssqlWarningJobs")
+}
+
+// GetVmwareVmWarningJobs ...
+func GetVmwareVmWarningJobs(rubrik *rubrikcdm.Credentials, clusterName string) {
+	getStatusJobs(rubrik, clusterName, "VmwareVm", "Warning", rubrikVmwareVmWarningJob, nil, "jobs.GetVmwareVmWarningJobs")
+}
@@ -3,10 +3,81 @@ package livemount
 import (
 	"log"
 	"time"
-	"github.com/rubrikinc/rubrik-sdk-for-go/rubrikcdm"
+
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rubrikinc/rubrik-client-for-prometheus/src/golang/util"
+	"github.com/rubrikinc/rubrik-sdk-for-go/rubrikcdm"
 )
 
+// liveMountType describes where to list the live mounts of one workload and which
+// fields of a mount hold its details. Where the API has changed between releases
+// several field names are tried in order.
+type liveMountType struct {
+	mountType          string
+	apiVersion         string
+	endpoint           string
+	sourceIDKeys       []string
+	sourceNameKeys     []string
+	mountNameKeys      []string
+	targetHostKeys     []string
+	creationDateKeys   []string
+	sourceNameEndpoint string // looked up by source ID when the mount has no source name
+}
+
+// liveMountTypes are the live mounts tracked by GetLiveMountAges.
+var liveMountTypes = []liveMountType{
+	{
+		mountType:        "Mssql",
+		apiVersion:       "v1",
+		endpoint:         "/mssql/db/mount",
+		sourceIDKeys:     []string{"sourceDatabaseId"},
+		sourceNameKeys:   []string{"sourceDatabaseName"},
+		mountNameKeys:    []string{"mountedDatabaseName"},
+		targetHostKeys:   []string{"targetRootName", "targetInstanceName"},
+		creationDateKeys: []string{"creationDate"},
+	},
+	{
+		mountType:          "VmwareVm",
+		apiVersion:         "v1",
+		endpoint:           "/vmware/vm/snapshot/mount",
+		sourceIDKeys:       []string{"vmId"},
+		mountNameKeys:      []string{"mountedVmName", "mountedVmId"},
+		targetHostKeys:     []string{"esxiHostName", "hostName", "hostId"},
+		creationDateKeys:   []string{"mountTimestamp", "creationDate"},
+		sourceNameEndpoint: "/vmware/vm/",
+	},
+	{
+		mountType:        "OracleDatabase",
+		apiVersion:       "internal",
+		endpoint:         "/oracle/db/mount",
+		sourceIDKeys:     []string{"sourceDatabaseId"},
+		sourceNameKeys:   []string{"sourceDatabaseName"},
+		mountNameKeys:    []string{"mountedDatabaseName", "mountedDatabaseId"},
+		targetHostKeys:   []string{"targetHostname", "targetHostName", "targetHostId"},
+		creationDateKeys: []string{"creationDate"},
+	},
+	{
+		mountType:        "ManagedVolume",
+		apiVersion:       "internal",
+		endpoint:         "/managed_volume/snapshot/export",
+		sourceIDKeys:     []string{"sourceManagedVolumeId"},
+		sourceNameKeys:   []string{"sourceManagedVolumeName"},
+		mountNameKeys:    []string{"exportName", "snapshotId"},
+		targetHostKeys:   []string{"hostPatterns"},
+		creationDateKeys: []string{"exportedDate", "creationDate", "createdDate"},
+	},
+	{
+		mountType:        "Fileset",
+		apiVersion:       "internal",
+		endpoint:         "/fileset/snapshot/mount",
+		sourceIDKeys:     []string{"filesetId", "sourceFilesetId"},
+		sourceNameKeys:   []string{"filesetName", "sourceFilesetName"},
+		mountNameKeys:    []string{"shareName", "mountPath"},
+		targetHostKeys:   []string{"hostName", "targetHostName"},
+		creationDateKeys: []string{"creationDate", "mountTimestamp"},
+	},
+}
+
 var (
 	// live mount stats
 	rubrikMssqlLiveMountAge = prometheus.NewGaugeVec(
@@ -21,32 +92,165 @@ var (
 			"mountedDatabaseName",
 		},
 	)
+	rubrikLiveMountAge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_live_mount_age_seconds",
+			Help: "Age of live mounts.",
+		},
+		[]string{
+			"clusterName",
+			"mountType",
+			"mountID",
+			"sourceObjectName",
+			"sourceObjectID",
+			"mountName",
+		},
+	)
+	rubrikLiveMountInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_live_mount_info",
+			Help: "Information for live mounts.",
+		},
+		[]string{
+			"clusterName",
+			"mountType",
+			"mountID",
+			"sourceObjectName",
+			"sourceObjectID",
+			"mountName",
+			"targetHost",
+			"owner",
+		},
+	)
+	rubrikLiveMountCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_live_mount_count",
+			Help: "Number of live mounts.",
+		},
+		[]string{
+			"clusterName",
+			"mountType",
+		},
+	)
 )
 
 func init() {
 	// live mount stats
 	prometheus.MustRegister(rubrikMssqlLiveMountAge)
+	prometheus.MustRegister(rubrikLiveMountAge)
+	prometheus.MustRegister(rubrikLiveMountInfo)
+	prometheus.MustRegister(rubrikLiveMountCount)
 }
 
-// GetMssqlLiveMountAges ...
-func GetMssqlLiveMountAges(rubrik *rubrikcdm.Credentials, clusterName string) {
-	mountData,err := rubrik.Get("v1","/mssql/db/mount", 60) // get our mssql live mount summary
-	if err != nil {
-		log.Printf("Error from livemount.GetMssqlLiveMountAges: ",err)
-		return
+// liveMount is one live mount, with the fields common to every mount type. The mount
+// APIs do not report the storage consumed by a mount, and its owner is read from the
+// audit events by setLiveMountOwners.
+type liveMount struct {
+	mountType  string
+	id         string
+	sourceID   string
+	sourceName string
+	mountName  string
+	targetHost string
+	owner      string
+	created    time.Time
+}
+
+// GetLiveMountAges ...
+func GetLiveMountAges(rubrik *rubrikcdm.Credentials, clusterName string) {
+	var mounts []liveMount
+	var readMountTypes []string
+	for _, mountType := range liveMountTypes {
+		theseMounts, err := getLiveMounts(rubrik, mountType)
+		if err != nil {
+			// not every mount type is available on every cluster version
+			log.Println("Error from livemount.GetLiveMountAges: ", err)
+			continue
+		}
+		mounts = append(mounts, theseMounts...)
+		readMountTypes = append(readMountTypes, mountType.mountType)
 	}
-	for _, v := range mountData.(map[string]interface{})["data"].([]interface{}) {
-		thisSourceDatabaseName := v.(map[string]interface{})["sourceDatabaseName"]
-		thisSourceDatabaseID := v.(map[string]interface{})["sourceDatabaseId"]
-		thisMountedDatabaseName := v.(map[string]interface{})["mountedDatabaseName"]
-		thisCreationDate := v.(map[string]interface{})["creationDate"]
-		mountTime, _ := time.Parse(time.RFC3339, thisCreationDate.(string))
-		age := time.Since(mountTime)
-		//fmt.Println(age.Seconds())
-		rubrikMssqlLiveMountAge.WithLabelValues(
+	if err := setLiveMountOwners(rubrik, mounts); err != nil {
+		log.Println("Error from livemount.GetLiveMountAges: ", err)
+	}
+	util.ResetGauges(
+		rubrikMssqlLiveMountAge,
+		rubrikLiveMountAge,
+		rubrikLiveMountInfo,
+		rubrikLiveMountCount)
+	for _, mountType := range readMountTypes {
+		rubrikLiveMountCount.WithLabelValues(clusterName, mountType).Set(0)
+	}
+	for _, mount := range mounts {
+		rubrikLiveMountCount.WithLabelValues(clusterName, mount.mountType).Inc()
+		rubrikLiveMountInfo.WithLabelValues(
+			clusterName,
+			mount.mountType,
+			mount.id,
+			mount.sourceName,
+			mount.sourceID,
+			mount.mountName,
+			mount.targetHost,
+			mount.owner).Set(1)
+		if mount.created.IsZero() {
+			continue
+		}
+		age := time.Since(mount.created)
+		rubrikLiveMountAge.WithLabelValues(
 			clusterName,
-			thisSourceDatabaseName.(string),
-			thisSourceDatabaseID.(string),
-			thisMountedDatabaseName.(string)).Set(age.Seconds())
+			mount.mountType,
+			mount.id,
+			mount.sourceName,
+			mount.sourceID,
+			mount.mountName).Set(age.Seconds())
+		if mount.mountType == "Mssql" {
+			rubrikMssqlLiveMountAge.WithLabelValues(
+				clusterName,
+				mount.sourceName,
+				mount.sourceID,
+				mount.mountName).Set(age.Seconds())
+		}
 	}
-}
\ No newline at end of file
+	violations := checkLiveMountPolicies(clusterName, mounts)
+	remediateLiveMounts(rubrik, clusterName, violations)
+}
+
+// getLiveMounts lists the live mounts of one mount type.
+func getLiveMounts(rubrik *rubrikcdm.Credentials, mountType liveMountType) ([]liveMount, error) {
+	mountData, err := rubrik.Get(mountType.apiVersion, mountType.endpoint, 60)
+	if err != nil {
+		return nil, err
+	}
+	sourceNames := map[string]string{}
+	var mounts []liveMount
+	data, _ := mountData.(map[string]interface{})["data"].([]interface{})
+	for _, v := range data {
+		thisMount := v.(map[string]interface{})
+		mount := liveMount{
+			mountType:  mountType.mountType,
+			id:         util.ObjectString(thisMount, "id"),
+			sourceID:   util.ObjectString(thisMount, mountType.sourceIDKeys...),
+			sourceName: util.ObjectString(thisMount, mountType.sourceNameKeys...),
+			mountName:  util.ObjectString(thisMount, mountType.mountNameKeys...),
+			targetHost: util.ObjectString(thisMount, mountType.targetHostKeys...),
+		}
+		for _, key := range mountType.creationDateKeys {
+			if creationDate, ok := thisMount[key].(string); ok {
+				mount.created, _ = time.Parse(time.RFC3339, creationDate)
+				break
+			}
+		}
+		if mount.sourceName == "null" && mountType.sourceNameEndpoint != "" && mount.sourceID != "null" {
+			if _, ok := sourceNames[mount.sourceID]; !ok {
+				sourceData, err := rubrik.Get(mountType.apiVersion, mountType.sourceNameEndpoint+mount.sourceID, 60)
+				if err != nil {
+					return nil, err
+				}
+				sourceNames[mount.sourceID] = util.ObjectString(sourceData.(map[string]interface{}), "name")
+			}
+			mount.sourceName = sourceNames[mount.sourceID]
+		}
+		mounts = append(mounts, mount)
+	}
+	return mounts, nil
+}
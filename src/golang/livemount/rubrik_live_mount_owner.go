@@ -0,0 +1,144 @@
+package livemount
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rubrikinc/rubrik-client-for-prometheus/src/golang/util"
+	"github.com/rubrikinc/rubrik-sdk-for-go/rubrikcdm"
+)
+
+// liveMountOwnerWindow is how far apart the audit event of a mount request and the
+// creation time of the live mount may be.
+const liveMountOwnerWindow = 10 * time.Minute
+
+// liveMountOwners caches the user who created each live mount by mount ID, or "null"
+// when no audit event of the mount request was found. Each mount is looked up once.
+var liveMountOwners = map[string]string{}
+
+// auditEvent is an audit event of the Rubrik cluster, such as a mount request.
+type auditEvent struct {
+	objectID string
+	time     time.Time
+	message  string
+	user     string
+}
+
+// setLiveMountOwners sets the owner of every live mount to the user of the audit event
+// that requested it, as the live mount APIs do not report who created a mount.
+func setLiveMountOwners(rubrik *rubrikcdm.Credentials, mounts []liveMount) error {
+	listedMounts := map[string]bool{}
+	var afterDate time.Time
+	for _, mount := range mounts {
+		listedMounts[mount.id] = true
+		if _, ok := liveMountOwners[mount.id]; ok || mount.created.IsZero() {
+			continue
+		}
+		if afterDate.IsZero() || mount.created.Before(afterDate) {
+			afterDate = mount.created
+		}
+	}
+	for mountID := range liveMountOwners {
+		if !listedMounts[mountID] {
+			delete(liveMountOwners, mountID)
+		}
+	}
+	if !afterDate.IsZero() {
+		events, err := getAuditEvents(rubrik, afterDate.Add(-liveMountOwnerWindow))
+		if err != nil {
+			setCachedLiveMountOwners(mounts)
+			return err
+		}
+		for _, mount := range mounts {
+			if _, ok := liveMountOwners[mount.id]; ok || mount.created.IsZero() {
+				continue
+			}
+			liveMountOwners[mount.id] = mountRequestUser(mount, events)
+		}
+	}
+	setCachedLiveMountOwners(mounts)
+	return nil
+}
+
+// setCachedLiveMountOwners sets the owner of every live mount from liveMountOwners.
+func setCachedLiveMountOwners(mounts []liveMount) {
+	for i := range mounts {
+		owner, ok := liveMountOwners[mounts[i].id]
+		if !ok {
+			owner = "null"
+		}
+		mounts[i].owner = owner
+	}
+}
+
+// mountRequestUser returns the user of the mount request audit event on the mount or
+// its source object closest to the creation of the mount, or "null" when there is none.
+func mountRequestUser(mount liveMount, events []auditEvent) string {
+	user := "null"
+	var closest time.Duration
+	for _, event := range events {
+		if event.objectID != mount.sourceID && event.objectID != mount.id {
+			continue
+		}
+		if !strings.Contains(strings.ToLower(event.message), "mount") {
+			continue
+		}
+		distance := event.time.Sub(mount.created)
+		if distance < 0 {
+			distance = -distance
+		}
+		if distance > liveMountOwnerWindow || (user != "null" && distance >= closest) {
+			continue
+		}
+		user, closest = event.user, distance
+	}
+	return user
+}
+
+// getAuditEvents returns the audit events after afterDate that name a user.
+func getAuditEvents(rubrik *rubrikcdm.Credentials, afterDate time.Time) ([]auditEvent, error) {
+	query := url.Values{}
+	query.Set("limit", "9999")
+	query.Set("event_type", "Audit")
+	query.Set("after_date", afterDate.UTC().Format("2006-01-02T15:04:05.000Z"))
+	eventData, err := rubrik.Get("v1", "/event/latest?"+query.Encode(), 60)
+	if err != nil {
+		return nil, err
+	}
+	var events []auditEvent
+	data, _ := eventData.(map[string]interface{})["data"].([]interface{})
+	for _, v := range data {
+		thisEvent, _ := v.(map[string]interface{})
+		latestEvent, ok := thisEvent["latestEvent"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		eventInfo, _ := latestEvent["eventInfo"].(string)
+		var info struct {
+			Message string                 `json:"message"`
+			Params  map[string]interface{} `json:"params"`
+		}
+		if json.Unmarshal([]byte(eventInfo), &info) != nil {
+			continue
+		}
+		event := auditEvent{
+			objectID: util.ObjectString(latestEvent, "objectId"),
+			message:  info.Message,
+			user:     "null",
+		}
+		event.time, _ = time.Parse(time.RFC3339, util.ObjectString(latestEvent, "time"))
+		// the user is one of the message parameters, such as ${username}
+		for key, value := range info.Params {
+			if user, ok := value.(string); ok && user != "" && strings.Contains(strings.ToLower(key), "user") {
+				event.user = user
+				break
+			}
+		}
+		if event.user != "null" {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
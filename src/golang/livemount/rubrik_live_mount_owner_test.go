@@ -0,0 +1,52 @@
+package livemount
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMountRequestUser(t *testing.T) {
+	created := time.Date(2021, 3, 10, 12, 0, 0, 0, time.UTC)
+	mount := liveMount{mountType: "VmwareVm", id: "mount-1", sourceID: "vm-1", created: created}
+	tests := []struct {
+		name   string
+		events []auditEvent
+		want   string
+	}{
+		{
+			name: "no events",
+			want: "null",
+		},
+		{
+			name: "mount request on the source object",
+			events: []auditEvent{
+				{objectID: "vm-1", time: created.Add(-time.Minute), message: "alice started a live mount of 'web01'", user: "alice"},
+			},
+			want: "alice",
+		},
+		{
+			name: "closest mount request",
+			events: []auditEvent{
+				{objectID: "vm-1", time: created.Add(-8 * time.Minute), message: "bob started a live mount of 'web01'", user: "bob"},
+				{objectID: "vm-1", time: created.Add(-2 * time.Minute), message: "alice started a live mount of 'web01'", user: "alice"},
+			},
+			want: "alice",
+		},
+		{
+			name: "other object, other request and outside the window",
+			events: []auditEvent{
+				{objectID: "vm-2", time: created, message: "bob started a live mount of 'db01'", user: "bob"},
+				{objectID: "vm-1", time: created, message: "bob changed the SLA domain of 'web01'", user: "bob"},
+				{objectID: "vm-1", time: created.Add(-time.Hour), message: "bob started a live mount of 'web01'", user: "bob"},
+			},
+			want: "null",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := mountRequestUser(mount, test.events); got != test.want {
+				t.Errorf("mountRequestUser() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
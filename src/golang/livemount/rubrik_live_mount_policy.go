@@ -0,0 +1,181 @@
+package livemount
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// live mount policy stats
+	rubrikLiveMountPolicyMaxAge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_live_mount_policy_max_age_seconds",
+			Help: "Maximum age of live mounts allowed by a live mount policy.",
+		},
+		[]string{
+			"clusterName",
+			"policy",
+		},
+	)
+	rubrikLiveMountPolicyViolation = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_live_mount_policy_violation",
+			Help: "Live mount older than the maximum age of a live mount policy.",
+		},
+		[]string{
+			"clusterName",
+			"policy",
+			"mountType",
+			"mountID",
+			"sourceObjectName",
+			"targetHost",
+		},
+	)
+	rubrikLiveMountPolicyTimeOverLimit = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_live_mount_policy_time_over_limit_seconds",
+			Help: "Time a live mount has been kept past the maximum age of a live mount policy.",
+		},
+		[]string{
+			"clusterName",
+			"policy",
+			"mountType",
+			"mountID",
+			"sourceObjectName",
+			"targetHost",
+		},
+	)
+)
+
+func init() {
+	// live mount policy stats
+	prometheus.MustRegister(rubrikLiveMountPolicyMaxAge)
+	prometheus.MustRegister(rubrikLiveMountPolicyViolation)
+	prometheus.MustRegister(rubrikLiveMountPolicyTimeOverLimit)
+}
+
+// liveMountPolicyFile is the format of the file read by LoadLiveMountPolicies.
+type liveMountPolicyFile struct {
+	Policies    []liveMountPolicy    `json:"policies"`
+	Remediation liveMountRemediation `json:"remediation"`
+}
+
+// liveMountPolicy limits the age of the live mounts it matches. Empty match fields
+// match every live mount.
+type liveMountPolicy struct {
+	Name         string  `json:"name"`
+	MountType    string  `json:"mountType"`    // Mssql, VmwareVm, OracleDatabase, ManagedVolume or Fileset
+	SourceObject string  `json:"sourceObject"` // regular expression matched against the source object name
+	TargetHost   string  `json:"targetHost"`   // regular expression matched against the target host
+	MaxAgeHours  float64 `json:"maxAgeHours"`
+
+	sourceObject *regexp.Regexp
+	targetHost   *regexp.Regexp
+}
+
+// liveMountPolicies are the policies loaded by LoadLiveMountPolicies.
+var liveMountPolicies []liveMountPolicy
+
+// LoadLiveMountPolicies reads the live mount policies checked by GetLiveMountAges from
+// a JSON file. Every policy needs a unique name and a maximum age.
+func LoadLiveMountPolicies(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var policyFile liveMountPolicyFile
+	if err := json.Unmarshal(data, &policyFile); err != nil {
+		return err
+	}
+	policyNames := map[string]bool{}
+	for i := range policyFile.Policies {
+		policy := &policyFile.Policies[i]
+		if policy.Name == "" {
+			return fmt.Errorf("live mount policy %d in %s has no name", i+1, path)
+		}
+		if policyNames[policy.Name] {
+			return fmt.Errorf("live mount policy %s is defined more than once in %s", policy.Name, path)
+		}
+		policyNames[policy.Name] = true
+		if policy.MaxAgeHours <= 0 {
+			return fmt.Errorf("live mount policy %s in %s needs a maxAgeHours greater than 0", policy.Name, path)
+		}
+		if policy.sourceObject, err = regexp.Compile(policy.SourceObject); err != nil {
+			return err
+		}
+		if policy.targetHost, err = regexp.Compile(policy.TargetHost); err != nil {
+			return err
+		}
+	}
+	for _, pattern := range policyFile.Remediation.Allowlist {
+		allowed, err := regexp.Compile(pattern)
+		if err != nil {
+			return err
+		}
+		policyFile.Remediation.allowlist = append(policyFile.Remediation.allowlist, allowed)
+	}
+	liveMountPolicies = policyFile.Policies
+	liveMountRemediationConfig = policyFile.Remediation
+	return nil
+}
+
+// maxAge returns the maximum age of the live mounts matched by the policy.
+func (p liveMountPolicy) maxAge() time.Duration {
+	return time.Duration(p.MaxAgeHours * float64(time.Hour))
+}
+
+// matches returns true when the policy applies to mount.
+func (p liveMountPolicy) matches(mount liveMount) bool {
+	if p.MountType != "" && p.MountType != mount.mountType {
+		return false
+	}
+	return p.sourceObject.MatchString(mount.sourceName) && p.targetHost.MatchString(mount.targetHost)
+}
+
+// checkLiveMountPolicies exports the policy violations of mounts, returning the
+// mounts which are past the maximum age of any policy.
+func checkLiveMountPolicies(clusterName string, mounts []liveMount) []liveMountViolation {
+	rubrikLiveMountPolicyMaxAge.Reset()
+	rubrikLiveMountPolicyViolation.Reset()
+	rubrikLiveMountPolicyTimeOverLimit.Reset()
+	var violations []liveMountViolation
+	for _, policy := range liveMountPolicies {
+		rubrikLiveMountPolicyMaxAge.WithLabelValues(clusterName, policy.Name).Set(policy.maxAge().Seconds())
+		for _, mount := range mounts {
+			if mount.created.IsZero() || !policy.matches(mount) {
+				continue
+			}
+			overLimit := time.Since(mount.created) - policy.maxAge()
+			if overLimit <= 0 {
+				continue
+			}
+			rubrikLiveMountPolicyViolation.WithLabelValues(
+				clusterName,
+				policy.Name,
+				mount.mountType,
+				mount.id,
+				mount.sourceName,
+				mount.targetHost).Set(1)
+			rubrikLiveMountPolicyTimeOverLimit.WithLabelValues(
+				clusterName,
+				policy.Name,
+				mount.mountType,
+				mount.id,
+				mount.sourceName,
+				mount.targetHost).Set(overLimit.Seconds())
+			violations = append(violations, liveMountViolation{policy.Name, mount})
+		}
+	}
+	return violations
+}
+
+// liveMountViolation is a live mount past the maximum age of a policy.
+type liveMountViolation struct {
+	policy string
+	mount  liveMount
+}
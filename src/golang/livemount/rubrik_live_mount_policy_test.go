@@ -0,0 +1,67 @@
+package livemount
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadLiveMountPolicies(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			file: `{"policies": [{"name": "sql-72h", "mountType": "Mssql", "maxAgeHours": 72}, {"name": "vm-24h", "mountType": "VmwareVm", "maxAgeHours": 24}]}`,
+		},
+		{
+			name:    "missing name",
+			file:    `{"policies": [{"mountType": "Mssql", "maxAgeHours": 72}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "duplicate name",
+			file:    `{"policies": [{"name": "sql", "maxAgeHours": 72}, {"name": "sql", "maxAgeHours": 24}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "missing maxAgeHours",
+			file:    `{"policies": [{"name": "sql-72h", "mountType": "Mssql"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "negative maxAgeHours",
+			file:    `{"policies": [{"name": "sql-72h", "maxAgeHours": -1}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid regular expression",
+			file:    `{"policies": [{"name": "sql-72h", "sourceObject": "(", "maxAgeHours": 72}]}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			liveMountPolicies = nil
+			dir, err := ioutil.TempDir("", "live_mount_policies")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "policies.json")
+			if err := ioutil.WriteFile(path, []byte(tt.file), 0600); err != nil {
+				t.Fatal(err)
+			}
+			err = LoadLiveMountPolicies(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadLiveMountPolicies() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && liveMountPolicies != nil {
+				t.Errorf("LoadLiveMountPolicies() kept %d policies from an invalid file", len(liveMountPolicies))
+			}
+		})
+	}
+}
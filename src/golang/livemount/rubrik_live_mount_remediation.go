@@ -0,0 +1,144 @@
+package livemount
+
+import (
+	"log"
+	"regexp"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rubrikinc/rubrik-sdk-for-go/rubrikcdm"
+)
+
+// unmountEndpoints are the APIs used to unmount each mount type which can be remediated.
+var unmountEndpoints = map[string]string{
+	"Mssql":    "/mssql/db/mount/",
+	"VmwareVm": "/vmware/vm/snapshot/mount/",
+}
+
+// remediationRetryInterval is how long to wait before acting on the same mount again,
+// as an unmount can take a while to be reflected in the live mount list.
+const remediationRetryInterval = 6 * time.Hour
+
+var (
+	// live mount remediation stats
+	rubrikLiveMountRemediationActions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rubrik_live_mount_remediation_actions_total",
+			Help: "Number of actions taken on live mounts past the maximum age of a live mount policy.",
+		},
+		[]string{
+			"clusterName",
+			"mountType",
+			"action",
+		},
+	)
+)
+
+func init() {
+	// live mount remediation stats
+	prometheus.MustRegister(rubrikLiveMountRemediationActions)
+}
+
+// liveMountRemediation configures the automatic unmount of live mounts past the
+// maximum age of a policy. It is disabled unless enabled, and only logs what it would
+// unmount unless dryRun is set to false.
+type liveMountRemediation struct {
+	Enabled            bool     `json:"enabled"`
+	DryRun             *bool    `json:"dryRun"`
+	Allowlist          []string `json:"allowlist"` // regular expressions matched against the source object and mount names of mounts never to unmount
+	MaxActionsPerCycle int      `json:"maxActionsPerCycle"`
+
+	allowlist []*regexp.Regexp
+}
+
+// live mount remediation state
+var (
+	liveMountRemediationConfig liveMountRemediation
+	remediatedLiveMounts       = map[string]time.Time{}
+)
+
+// dryRun returns true unless dry run has been turned off.
+func (r liveMountRemediation) dryRun() bool {
+	return r.DryRun == nil || *r.DryRun
+}
+
+// maxActions returns the maximum number of mounts to act on per collection.
+func (r liveMountRemediation) maxActions() int {
+	if r.MaxActionsPerCycle <= 0 {
+		return 1
+	}
+	return r.MaxActionsPerCycle
+}
+
+// allowed returns true when mount is on the allowlist.
+func (r liveMountRemediation) allowed(mount liveMount) bool {
+	for _, allowed := range r.allowlist {
+		if allowed.MatchString(mount.sourceName) || allowed.MatchString(mount.mountName) {
+			return true
+		}
+	}
+	return false
+}
+
+// remediateLiveMounts unmounts the SQL DB and VMware VM live mounts in violations, when
+// remediation is enabled.
+func remediateLiveMounts(rubrik *rubrikcdm.Credentials, clusterName string, violations []liveMountViolation) {
+	applyLiveMountRemediation(clusterName, violations, func(endpoint string) error {
+		_, err := rubrik.Delete("v1", endpoint, 60)
+		return err
+	})
+}
+
+// applyLiveMountRemediation acts on violations according to liveMountRemediationConfig,
+// calling unmount with the endpoint of each mount to delete.
+func applyLiveMountRemediation(clusterName string, violations []liveMountViolation, unmount func(endpoint string) error) {
+	config := liveMountRemediationConfig
+	if !config.Enabled {
+		return
+	}
+	for id, remediated := range remediatedLiveMounts {
+		if time.Since(remediated) > remediationRetryInterval {
+			delete(remediatedLiveMounts, id)
+		}
+	}
+	actions := 0
+	for _, violation := range violations {
+		mount := violation.mount
+		endpoint, ok := unmountEndpoints[mount.mountType]
+		if !ok {
+			continue
+		}
+		// never build an unmount request from a mount without an ID
+		if mount.id == "" || mount.id == "null" {
+			rubrikLiveMountRemediationActions.WithLabelValues(clusterName, mount.mountType, "skipped_no_id").Inc()
+			continue
+		}
+		if _, ok := remediatedLiveMounts[mount.id]; ok {
+			continue
+		}
+		remediatedLiveMounts[mount.id] = time.Now()
+		if config.allowed(mount) {
+			rubrikLiveMountRemediationActions.WithLabelValues(clusterName, mount.mountType, "skipped_allowlist").Inc()
+			continue
+		}
+		if actions >= config.maxActions() {
+			// try again on the next collection
+			delete(remediatedLiveMounts, mount.id)
+			rubrikLiveMountRemediationActions.WithLabelValues(clusterName, mount.mountType, "skipped_limit").Inc()
+			continue
+		}
+		actions++
+		if config.dryRun() {
+			log.Printf("Dry run: would unmount %s live mount %s of %s, past the maximum age of policy %s", mount.mountType, mount.id, mount.sourceName, violation.policy)
+			rubrikLiveMountRemediationActions.WithLabelValues(clusterName, mount.mountType, "dry_run").Inc()
+			continue
+		}
+		log.Printf("Unmounting %s live mount %s of %s, past the maximum age of policy %s", mount.mountType, mount.id, mount.sourceName, violation.policy)
+		if err := unmount(endpoint + mount.id); err != nil {
+			log.Println("Error from livemount.GetLiveMountAges: ", err)
+			rubrikLiveMountRemediationActions.WithLabelValues(clusterName, mount.mountType, "unmount_failed").Inc()
+			continue
+		}
+		rubrikLiveMountRemediationActions.WithLabelValues(clusterName, mount.mountType, "unmount").Inc()
+	}
+}
@@ -0,0 +1,105 @@
+package livemount
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestApplyLiveMountRemediation(t *testing.T) {
+	dryRunOff := false
+	dryRunOn := true
+	violations := []liveMountViolation{
+		{"sql-72h", liveMount{mountType: "Mssql", id: "mount-1", sourceName: "sales", mountName: "sales_lm"}},
+		{"sql-72h", liveMount{mountType: "Mssql", id: "mount-2", sourceName: "perf-baseline-01", mountName: "perf_lm"}},
+		{"vm-24h", liveMount{mountType: "VmwareVm", id: "mount-3", sourceName: "web01", mountName: "web01 03-10 12:00"}},
+		{"oracle-24h", liveMount{mountType: "OracleDatabase", id: "mount-4", sourceName: "orcl"}},
+		{"sql-72h", liveMount{mountType: "Mssql", id: "null", sourceName: "unknown"}},
+	}
+	tests := []struct {
+		name        string
+		config      liveMountRemediation
+		unmountErr  error
+		wantUnmount []string
+	}{
+		{
+			name:   "disabled",
+			config: liveMountRemediation{Enabled: false, DryRun: &dryRunOff, MaxActionsPerCycle: 10},
+		},
+		{
+			name:   "dry run by default",
+			config: liveMountRemediation{Enabled: true, MaxActionsPerCycle: 10},
+		},
+		{
+			name:   "dry run",
+			config: liveMountRemediation{Enabled: true, DryRun: &dryRunOn, MaxActionsPerCycle: 10},
+		},
+		{
+			name:        "unmount all except unsupported types and missing IDs",
+			config:      liveMountRemediation{Enabled: true, DryRun: &dryRunOff, MaxActionsPerCycle: 10},
+			wantUnmount: []string{"/mssql/db/mount/mount-1", "/mssql/db/mount/mount-2", "/vmware/vm/snapshot/mount/mount-3"},
+		},
+		{
+			name: "allowlist",
+			config: liveMountRemediation{Enabled: true, DryRun: &dryRunOff, MaxActionsPerCycle: 10,
+				allowlist: []*regexp.Regexp{regexp.MustCompile("^perf-baseline-"), regexp.MustCompile("^web01 ")}},
+			wantUnmount: []string{"/mssql/db/mount/mount-1"},
+		},
+		{
+			name:        "default cap of one",
+			config:      liveMountRemediation{Enabled: true, DryRun: &dryRunOff},
+			wantUnmount: []string{"/mssql/db/mount/mount-1"},
+		},
+		{
+			name:        "cap",
+			config:      liveMountRemediation{Enabled: true, DryRun: &dryRunOff, MaxActionsPerCycle: 2},
+			wantUnmount: []string{"/mssql/db/mount/mount-1", "/mssql/db/mount/mount-2"},
+		},
+		{
+			name:        "failed unmounts count against the cap",
+			config:      liveMountRemediation{Enabled: true, DryRun: &dryRunOff, MaxActionsPerCycle: 2},
+			unmountErr:  errors.New("unmount failed"),
+			wantUnmount: []string{"/mssql/db/mount/mount-1", "/mssql/db/mount/mount-2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			liveMountRemediationConfig = tt.config
+			remediatedLiveMounts = map[string]time.Time{}
+			var unmounted []string
+			applyLiveMountRemediation("cluster", violations, func(endpoint string) error {
+				unmounted = append(unmounted, endpoint)
+				return tt.unmountErr
+			})
+			if !reflect.DeepEqual(unmounted, tt.wantUnmount) {
+				t.Errorf("unmounted %v, want %v", unmounted, tt.wantUnmount)
+			}
+		})
+	}
+}
+
+func TestApplyLiveMountRemediationRetry(t *testing.T) {
+	dryRunOff := false
+	liveMountRemediationConfig = liveMountRemediation{Enabled: true, DryRun: &dryRunOff, MaxActionsPerCycle: 1}
+	remediatedLiveMounts = map[string]time.Time{}
+	violations := []liveMountViolation{
+		{"sql-72h", liveMount{mountType: "Mssql", id: "mount-1"}},
+		{"sql-72h", liveMount{mountType: "Mssql", id: "mount-2"}},
+	}
+	var unmounted []string
+	unmount := func(endpoint string) error {
+		unmounted = append(unmounted, endpoint)
+		return nil
+	}
+	// the mount skipped for the cap is unmounted on the next collection, and the
+	// unmounted one is not retried while it is still listed
+	applyLiveMountRemediation("cluster", violations, unmount)
+	applyLiveMountRemediation("cluster", violations, unmount)
+	applyLiveMountRemediation("cluster", violations, unmount)
+	want := []string{"/mssql/db/mount/mount-1", "/mssql/db/mount/mount-2"}
+	if !reflect.DeepEqual(unmounted, want) {
+		t.Errorf("unmounted %v, want %v", unmounted, want)
+	}
+}
@@ -14,8 +14,10 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rubrikinc/rubrik-client-for-prometheus/src/golang/jobs"
 	"github.com/rubrikinc/rubrik-client-for-prometheus/src/golang/livemount"
@@ -46,6 +48,85 @@ func main() {
 	clusterName := clusterDetails.(map[string]interface{})["name"]
 	log.Printf("Cluster name: " + clusterName.(string))
 
+	// forward events to a log pipeline
+	lokiURL, _ := os.LookupEnv("RUBRIK_EVENT_LOKI_URL")
+	if lokiURL != "" {
+		jobs.EnableLokiForwarding(lokiURL)
+		log.Printf("Forwarding events to Loki at " + lokiURL)
+	}
+	eventLogFile, _ := os.LookupEnv("RUBRIK_EVENT_LOG_FILE")
+	if eventLogFile != "" {
+		eventLogMaxBytes := int64(104857600)
+		if maxBytesEnv, _ := os.LookupEnv("RUBRIK_EVENT_LOG_MAX_BYTES"); maxBytesEnv != "" {
+			eventLogMaxBytes, err = strconv.ParseInt(maxBytesEnv, 10, 64)
+			if err != nil {
+				log.Printf("Error from main.go:")
+				log.Fatal(err)
+			}
+		}
+		eventLogMaxBackups := 5
+		if maxBackupsEnv, _ := os.LookupEnv("RUBRIK_EVENT_LOG_MAX_BACKUPS"); maxBackupsEnv != "" {
+			eventLogMaxBackups, err = strconv.Atoi(maxBackupsEnv)
+			if err != nil {
+				log.Printf("Error from main.go:")
+				log.Fatal(err)
+			}
+		}
+		err = jobs.EnableFileForwarding(eventLogFile, eventLogMaxBytes, eventLogMaxBackups)
+		if err != nil {
+			log.Printf("Error from main.go:")
+			log.Fatal(err)
+		}
+		log.Printf("Writing events to " + eventLogFile)
+	}
+
+	// track SLA domain changes across restarts
+	slaStateFile, _ := os.LookupEnv("RUBRIK_SLA_STATE_FILE")
+	slaChangeLog, _ := os.LookupEnv("RUBRIK_SLA_CHANGE_LOG")
+	err = objectprotection.ConfigureSlaChangeTracking(slaStateFile, slaChangeLog == "true")
+	if err != nil {
+		log.Printf("Error from main.go:")
+		log.Fatal(err)
+	}
+
+	// track snappable SLA domain assignment changes across restarts
+	slaAssignmentStateFile, _ := os.LookupEnv("RUBRIK_SLA_ASSIGNMENT_STATE_FILE")
+	err = objectprotection.ConfigureSlaAssignmentTracking(slaAssignmentStateFile)
+	if err != nil {
+		log.Printf("Error from main.go:")
+		log.Fatal(err)
+	}
+
+	// live mount policies
+	liveMountPolicyFile, _ := os.LookupEnv("RUBRIK_LIVE_MOUNT_POLICY_FILE")
+	if liveMountPolicyFile != "" {
+		err = livemount.LoadLiveMountPolicies(liveMountPolicyFile)
+		if err != nil {
+			log.Printf("Error from main.go:")
+			log.Fatal(err)
+		}
+	}
+
+	// RPO breach grace period
+	if rpoGraceEnv, _ := os.LookupEnv("RUBRIK_RPO_GRACE_MINUTES"); rpoGraceEnv != "" {
+		rpoGraceMinutes, err := strconv.Atoi(rpoGraceEnv)
+		if err != nil {
+			log.Printf("Error from main.go:")
+			log.Fatal(err)
+		}
+		objectprotection.SetRpoGracePeriod(time.Duration(rpoGraceMinutes) * time.Minute)
+	}
+
+	// recovery job window
+	if recoveryWindowEnv, _ := os.LookupEnv("RUBRIK_RECOVERY_WINDOW_HOURS"); recoveryWindowEnv != "" {
+		recoveryWindowHours, err := strconv.Atoi(recoveryWindowEnv)
+		if err != nil {
+			log.Printf("Error from main.go:")
+			log.Fatal(err)
+		}
+		jobs.SetRecoveryJobWindow(time.Duration(recoveryWindowHours) * time.Hour)
+	}
+
 	// get storage summary
 	go func() {
 		for {
@@ -88,6 +169,39 @@ func main() {
 		}
 	}()
 
+	// warning job details
+	go func() {
+		for {
+			jobs.GetMssqlWarningJobs(rubrik, clusterName.(string))
+			jobs.GetVmwareVmWarningJobs(rubrik, clusterName.(string))
+			time.Sleep(time.Duration(5) * time.Minute)
+		}
+	}()
+
+	// running and queued job details
+	go func() {
+		for {
+			jobs.GetRunningJobs(rubrik, clusterName.(string))
+			time.Sleep(time.Duration(1) * time.Minute)
+		}
+	}()
+
+	// recovery job details
+	go func() {
+		for {
+			jobs.GetRecoveryJobs(rubrik, clusterName.(string))
+			time.Sleep(time.Duration(15) * time.Minute)
+		}
+	}()
+
+	// event stream counters
+	go func() {
+		for {
+			jobs.TailEvents(rubrik, clusterName.(string))
+			time.Sleep(time.Duration(1) * time.Minute)
+		}
+	}()
+
 	// SQL DB capacity stats
 	go func() {
 		for {
@@ -96,6 +210,22 @@ func main() {
 		}
 	}()
 
+	// SQL Server instance, availability group and DB state
+	go func() {
+		for {
+			stats.GetMssqlStateStats(rubrik, clusterName.(string))
+			time.Sleep(time.Duration(15) * time.Minute)
+		}
+	}()
+
+	// SQL DB log backup stats
+	go func() {
+		for {
+			stats.GetMssqlLogBackupStats(rubrik, clusterName.(string))
+			time.Sleep(time.Duration(15) * time.Minute)
+		}
+	}()
+
 	// Oracle DB capacity stats
 	go func() {
 		for {
@@ -104,6 +234,14 @@ func main() {
 		}
 	}()
 
+	// Oracle DB state and archive log backup stats
+	go func() {
+		for {
+			stats.GetOracleDbStats(rubrik, clusterName.(string))
+			time.Sleep(time.Duration(15) * time.Minute)
+		}
+	}()
+
 	// VMware vSphere VM capacity stats
 	go func() {
 		for {
@@ -112,6 +250,31 @@ func main() {
 		}
 	}()
 
+	// VMware vSphere infrastructure health stats
+	go func() {
+		for {
+			stats.GetVSphereHealthStats(rubrik, clusterName.(string))
+			time.Sleep(time.Duration(1) * time.Hour)
+		}
+	}()
+
+	// fileset stats
+	go func() {
+		for {
+			stats.GetFilesetCapacityStats(rubrik, clusterName.(string))
+			stats.GetFilesetStateStats(rubrik, clusterName.(string))
+			time.Sleep(time.Duration(1) * time.Hour)
+		}
+	}()
+
+	// snapshot inventory stats
+	go func() {
+		for {
+			stats.GetSnapshotInventoryStats(rubrik, clusterName.(string))
+			time.Sleep(time.Duration(1) * time.Hour)
+		}
+	}()
+
 	// Rubrik Snappable slaDomain
 	go func() {
 		for {
@@ -128,10 +291,26 @@ func main() {
 		}
 	}()
 
+	// Rubrik object snapshot status
+	go func() {
+		for {
+			objectprotection.GetObjectSnapshotStatus(rubrik, clusterName.(string))
+			time.Sleep(time.Duration(15) * time.Minute)
+		}
+	}()
+
+	// Rubrik unprotected objects
+	go func() {
+		for {
+			objectprotection.GetUnprotectedObjects(rubrik, clusterName.(string))
+			time.Sleep(time.Duration(1) * time.Hour)
+		}
+	}()
+
 	// get live mount stats
 	go func() {
 		for {
-			livemount.GetMssqlLiveMountAges(rubrik, clusterName.(string))
+			livemount.GetLiveMountAges(rubrik, clusterName.(string))
 			time.Sleep(time.Duration(1) * time.Hour)
 		}
 	}()
@@ -144,9 +323,13 @@ func main() {
 		}
 	}()
 
-	// The Handler function provides a default handler to expose metrics
+	// The HandlerFor function provides a handler to expose metrics
 	// via an HTTP server. "/metrics" is the usual endpoint for that.
-	http.Handle("/metrics", promhttp.Handler())
+	// OpenMetrics is enabled so that exemplars are served to scrapers asking for it.
+	http.Handle("/metrics", promhttp.InstrumentMetricHandler(
+		prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}),
+	))
 	log.Printf("Starting on HTTP port " + httpPort)
 	log.Fatal(http.ListenAndServe(":"+httpPort, nil))
 }
@@ -0,0 +1,168 @@
+package objectprotection
+
+import (
+	"log"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rubrikinc/rubrik-client-for-prometheus/src/golang/util"
+	"github.com/rubrikinc/rubrik-sdk-for-go/rubrikcdm"
+)
+
+// rpoGracePeriod is how late a snapshot may be before the RPO of an object counts as
+// breached, set by SetRpoGracePeriod.
+var rpoGracePeriod = 15 * time.Minute
+
+var (
+	// Rubrik object snapshot status
+	objectLastSnapshotTimestamp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_object_last_snapshot_timestamp_seconds",
+			Help: "Time of the latest local snapshot of a protected object.",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectType",
+			"objectID",
+			"location",
+			"slaDomain",
+		},
+	)
+	objectRpoBreached = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_object_rpo_breached",
+			Help: "Whether the latest snapshot of a protected object is older than its SLA domain frequency plus the grace period (1 is breached, 0 is within SLA).",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectType",
+			"objectID",
+			"location",
+			"slaDomain",
+		},
+	)
+)
+
+func init() {
+	// object snapshot status
+	prometheus.MustRegister(objectLastSnapshotTimestamp)
+	prometheus.MustRegister(objectRpoBreached)
+}
+
+// objectSnapshotKey identifies one object in the object snapshot status metrics.
+type objectSnapshotKey struct {
+	objectName string
+	objectType string
+	objectID   string
+	location   string
+	slaDomain  string
+}
+
+// objectSnapshotStatus is the latest snapshot of one object, zero when it has none, and
+// whether it breaches the RPO of its SLA domain, which is unknown when the SLA domain has
+// no snapshot frequency.
+type objectSnapshotStatus struct {
+	lastSnapshot time.Time
+	breached     float64
+	hasRpo       bool
+}
+
+// SetRpoGracePeriod sets how late a snapshot may be before the RPO of an object counts
+// as breached.
+func SetRpoGracePeriod(gracePeriod time.Duration) {
+	rpoGracePeriod = gracePeriod
+}
+
+// GetObjectSnapshotStatus ...
+func GetObjectSnapshotStatus(rubrik *rubrikcdm.Credentials, clusterName string) {
+	if err := loadSlaDefinitions(rubrik); err != nil {
+		log.Println("Error from objectprotection.GetObjectSnapshotStatus: ", err)
+	}
+	reportData, err := rubrik.Get("internal", "/report?report_template=ObjectProtectionSummary&report_type=Canned", 60) // get our object protection summary report
+	if err != nil {
+		log.Println("Error from objectprotection.GetObjectSnapshotStatus: ", err)
+		return
+	}
+	reports := reportData.(map[string]interface{})["data"].([]interface{})
+	reportID := reports[0].(map[string]interface{})["id"]
+	objects := map[objectSnapshotKey]objectSnapshotStatus{}
+	body := map[string]interface{}{
+		"limit": 100,
+	}
+	for {
+		tableData, err := rubrik.Post("internal", "/report/"+reportID.(string)+"/table", body, 60) // get our first page of data for the report
+		if err != nil {
+			log.Println("Error from objectprotection.GetObjectSnapshotStatus: ", err)
+			return
+		}
+		dataGrid := tableData.(map[string]interface{})["dataGrid"].([]interface{})
+		hasMore := tableData.(map[string]interface{})["hasMore"].(bool)
+		cursor := tableData.(map[string]interface{})["cursor"]
+		columns := tableData.(map[string]interface{})["columns"].([]interface{})
+		for _, v := range dataGrid {
+			thisObjectID, thisObjectName, thisObjectType, thisLocation, thisSlaDomain := "null", "null", "null", "null", "null"
+			var thisLastSnapshot time.Time
+			for i := 0; i < len(columns); i++ {
+				value, ok := v.([]interface{})[i].(string)
+				if !ok {
+					continue
+				}
+				switch columns[i] {
+				case "ObjectId", "ObjectLinkingId":
+					thisObjectID = value
+				case "ObjectName":
+					thisObjectName = value
+				case "ObjectType":
+					thisObjectType = value
+				case "Location":
+					thisLocation = value
+				case "SlaDomain":
+					thisSlaDomain = value
+				case "LatestLocalSnapshot", "LastSnapshot":
+					thisLastSnapshot = util.ParseReportTime(value)
+				}
+			}
+			thisObject := objectSnapshotKey{thisObjectName, thisObjectType, thisObjectID, thisLocation, thisSlaDomain}
+			thisStatus := objectSnapshotStatus{lastSnapshot: thisLastSnapshot}
+			if definition, ok := getSlaDefinition(thisSlaDomain); ok && definition.expectedSnapshotInterval() > 0 {
+				thisStatus.hasRpo = true
+				// an object that has never been backed up breaches its RPO
+				if thisLastSnapshot.IsZero() || time.Since(thisLastSnapshot) > definition.expectedSnapshotInterval()+rpoGracePeriod {
+					thisStatus.breached = 1
+				}
+			}
+			objects[thisObject] = thisStatus
+		}
+		if !hasMore {
+			break
+		}
+		body = map[string]interface{}{
+			"limit":  1000,
+			"cursor": cursor,
+		}
+	}
+	util.ResetGauges(objectLastSnapshotTimestamp, objectRpoBreached)
+	for k, status := range objects {
+		if !status.lastSnapshot.IsZero() {
+			objectLastSnapshotTimestamp.WithLabelValues(
+				clusterName,
+				k.objectName,
+				k.objectType,
+				k.objectID,
+				k.location,
+				k.slaDomain).Set(float64(status.lastSnapshot.Unix()))
+		}
+		if !status.hasRpo {
+			continue
+		}
+		objectRpoBreached.WithLabelValues(
+			clusterName,
+			k.objectName,
+			k.objectType,
+			k.objectID,
+			k.location,
+			k.slaDomain).Set(status.breached)
+	}
+}
@@ -0,0 +1,133 @@
+package objectprotection
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rubrikinc/rubrik-client-for-prometheus/src/golang/util"
+	"github.com/rubrikinc/rubrik-sdk-for-go/rubrikcdm"
+)
+
+// slaFrequencyUnits is the longest time covered by one unit of each SLA frequency tier,
+// so that a monthly snapshot taken on the last day of a long month is still on time.
+// Retention is given in the same unit as the frequency of its tier.
+var slaFrequencyUnits = map[string]time.Duration{
+	"hourly":    time.Hour,
+	"daily":     24 * time.Hour,
+	"weekly":    7 * 24 * time.Hour,
+	"monthly":   31 * 24 * time.Hour,
+	"quarterly": 92 * 24 * time.Hour,
+	"yearly":    366 * 24 * time.Hour,
+}
+
+// slaFrequency is one frequency tier of an SLA domain, such as take a snapshot every
+// 4 hours and keep it for 24 hours.
+type slaFrequency struct {
+	frequency float64
+	retention float64
+}
+
+// slaDefinition is the part of an SLA domain needed to judge the objects assigned to it.
+type slaDefinition struct {
+	id          string
+	name        string
+	frequencies map[string]slaFrequency
+}
+
+// SLA domains by name, as last fetched by GetSlaDomainSummary
+var (
+	slaDefinitionsMutex  sync.RWMutex
+	slaDefinitions       = map[string]slaDefinition{}
+	slaDefinitionsLoaded bool
+)
+
+// newSlaDefinition builds an slaDefinition from the frequencies of a v2 SLA domain.
+func newSlaDefinition(id, name string, frequencies interface{}) slaDefinition {
+	definition := slaDefinition{
+		id:          id,
+		name:        name,
+		frequencies: map[string]slaFrequency{},
+	}
+	tiers, _ := frequencies.(map[string]interface{})
+	for tier := range slaFrequencyUnits {
+		thisTier, ok := tiers[tier].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		thisFrequency, _ := thisTier["frequency"].(float64)
+		thisRetention, _ := thisTier["retention"].(float64)
+		definition.frequencies[tier] = slaFrequency{thisFrequency, thisRetention}
+	}
+	return definition
+}
+
+// expectedSnapshotInterval returns the longest time allowed between two snapshots, or
+// zero when the SLA domain takes no scheduled snapshots.
+func (d slaDefinition) expectedSnapshotInterval() time.Duration {
+	var interval time.Duration
+	for tier, frequency := range d.frequencies {
+		if frequency.frequency <= 0 {
+			continue
+		}
+		tierInterval := time.Duration(frequency.frequency * float64(slaFrequencyUnits[tier]))
+		if interval == 0 || tierInterval < interval {
+			interval = tierInterval
+		}
+	}
+	return interval
+}
+
+// maxRetention returns the longest time any snapshot taken by the SLA domain is kept.
+func (d slaDefinition) maxRetention() time.Duration {
+	var retention time.Duration
+	for tier, frequency := range d.frequencies {
+		tierRetention := time.Duration(frequency.retention * float64(slaFrequencyUnits[tier]))
+		if tierRetention > retention {
+			retention = tierRetention
+		}
+	}
+	return retention
+}
+
+// setSlaDefinitions replaces the known SLA domains.
+func setSlaDefinitions(definitions map[string]slaDefinition) {
+	slaDefinitionsMutex.Lock()
+	defer slaDefinitionsMutex.Unlock()
+	slaDefinitions = definitions
+	slaDefinitionsLoaded = true
+}
+
+// loadSlaDefinitions reads the SLA domains when GetSlaDomainSummary has not done so yet,
+// as the collectors which judge objects against their SLA domain start at the same time.
+func loadSlaDefinitions(rubrik *rubrikcdm.Credentials) error {
+	slaDefinitionsMutex.RLock()
+	loaded := slaDefinitionsLoaded
+	slaDefinitionsMutex.RUnlock()
+	if loaded {
+		return nil
+	}
+	slaData, err := rubrik.Get("v2", "/sla_domain", 60)
+	if err != nil {
+		return err
+	}
+	definitions := map[string]slaDefinition{}
+	slaEntities, _ := slaData.(map[string]interface{})["data"].([]interface{})
+	for _, v := range slaEntities {
+		thisSla, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		thisSlaDomainName := util.ObjectString(thisSla, "name")
+		definitions[thisSlaDomainName] = newSlaDefinition(util.ObjectString(thisSla, "id"), thisSlaDomainName, thisSla["frequencies"])
+	}
+	setSlaDefinitions(definitions)
+	return nil
+}
+
+// getSlaDefinition returns the SLA domain with the given name.
+func getSlaDefinition(name string) (slaDefinition, bool) {
+	slaDefinitionsMutex.RLock()
+	defer slaDefinitionsMutex.RUnlock()
+	definition, ok := slaDefinitions[name]
+	return definition, ok
+}
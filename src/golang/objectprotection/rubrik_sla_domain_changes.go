@@ -0,0 +1,194 @@
+package objectprotection
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// slaConfigKeys are the parts of an SLA domain which change its protection. Object
+// counts and other fields maintained by the cluster are left out.
+var slaConfigKeys = []string{
+	"name",
+	"frequencies",
+	"allowedBackupWindows",
+	"firstFullAllowedBackupWindows",
+	"localRetentionLimit",
+	"maxLocalRetentionLimit",
+	"archivalSpecs",
+	"replicationSpecs",
+	"advancedUiConfig",
+	"isRetentionLocked",
+}
+
+var (
+	// Rubrik SLA Domain configuration changes
+	slaDomainConfigHash = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_sla_domain_config_hash",
+			Help: "Hash of the effective configuration of an SLA domain",
+		},
+		[]string{
+			"primaryClusterId",
+			"slaDomainName",
+			"slaDomainId",
+		},
+	)
+	slaDomainChanges = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rubrik_sla_domain_changes_total",
+			Help: "Number of changes seen to the configuration of an SLA domain",
+		},
+		[]string{
+			"primaryClusterId",
+			"slaDomainName",
+			"slaDomainId",
+		},
+	)
+)
+
+// SLA domain change tracking state, set by ConfigureSlaChangeTracking
+var (
+	slaStateFile      string
+	slaLogChanges     bool
+	slaDomainConfigs  = map[string]slaDomainConfig{}
+	slaConfigsChanged bool
+)
+
+func init() {
+	prometheus.MustRegister(slaDomainConfigHash)
+	prometheus.MustRegister(slaDomainChanges)
+}
+
+// slaDomainConfig is the last seen configuration of an SLA domain, flattened to
+// field paths such as frequencies.daily.retention.
+type slaDomainConfig struct {
+	Hash   string            `json:"hash"`
+	Config map[string]string `json:"config"`
+}
+
+// slaConfigChange is one changed field, as written to the log.
+type slaConfigChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+// ConfigureSlaChangeTracking persists the SLA domain configurations seen by
+// GetSlaDomainSummary to stateFile, so that changes made while the agent is not running
+// are still counted, and optionally logs what changed.
+func ConfigureSlaChangeTracking(stateFile string, logChanges bool) error {
+	slaLogChanges = logChanges
+	if stateFile == "" {
+		return nil
+	}
+	if err := loadStateFile(stateFile, &slaDomainConfigs); err != nil {
+		return err
+	}
+	slaStateFile = stateFile
+	return nil
+}
+
+// trackSlaDomainChanges compares the configuration of an SLA domain with the last one seen.
+func trackSlaDomainChanges(clusterID, slaDomainName, slaDomainID string, sla map[string]interface{}) {
+	config := map[string]string{}
+	for _, key := range slaConfigKeys {
+		if value, ok := sla[key]; ok {
+			flattenSlaConfig(key, value, config)
+		}
+	}
+	fields := make([]string, 0, len(config))
+	for field := range config {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	hash := sha256.New()
+	for _, field := range fields {
+		fmt.Fprintf(hash, "%s=%s\n", field, config[field])
+	}
+	sum := hash.Sum(nil)
+	// the first 32 bits of the hash fit in a float64 without losing precision
+	slaDomainConfigHash.WithLabelValues(clusterID, slaDomainName, slaDomainID).Set(float64(binary.BigEndian.Uint32(sum)))
+	slaDomainChanges.WithLabelValues(clusterID, slaDomainName, slaDomainID).Add(0)
+
+	thisConfig := slaDomainConfig{Hash: fmt.Sprintf("%x", sum), Config: config}
+	previousConfig, seen := slaDomainConfigs[slaDomainID]
+	if seen && previousConfig.Hash == thisConfig.Hash {
+		return
+	}
+	slaDomainConfigs[slaDomainID] = thisConfig
+	slaConfigsChanged = true
+	if !seen {
+		return
+	}
+	slaDomainChanges.WithLabelValues(clusterID, slaDomainName, slaDomainID).Inc()
+	if !slaLogChanges {
+		return
+	}
+	var changes []slaConfigChange
+	for field, value := range config {
+		if previousValue, ok := previousConfig.Config[field]; !ok || previousValue != value {
+			changes = append(changes, slaConfigChange{Field: field, Old: previousValue, New: value})
+		}
+	}
+	for field, previousValue := range previousConfig.Config {
+		if _, ok := config[field]; !ok {
+			changes = append(changes, slaConfigChange{Field: field, Old: previousValue})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	diff, err := json.Marshal(map[string]interface{}{
+		"slaDomainName": slaDomainName,
+		"slaDomainId":   slaDomainID,
+		"changes":       changes,
+	})
+	if err != nil {
+		log.Println("Error from objectprotection.GetSlaDomainSummary: ", err)
+		return
+	}
+	log.Println("SLA domain changed: " + string(diff))
+}
+
+// saveSlaDomainChanges writes the SLA domain configurations to the state file when
+// they have changed.
+func saveSlaDomainChanges() {
+	if slaStateFile == "" || !slaConfigsChanged {
+		return
+	}
+	if err := saveStateFile(slaStateFile, slaDomainConfigs); err != nil {
+		log.Println("Error from objectprotection.GetSlaDomainSummary: ", err)
+		return
+	}
+	slaConfigsChanged = false
+}
+
+// flattenSlaConfig adds value to config under its field path, such as
+// archivalSpecs.0.archivalThreshold.
+func flattenSlaConfig(path string, value interface{}, config map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, w := range v {
+			flattenSlaConfig(path+"."+key, w, config)
+		}
+	case []interface{}:
+		for i, w := range v {
+			flattenSlaConfig(path+"."+strconv.Itoa(i), w, config)
+		}
+	case string:
+		config[path] = v
+	case float64:
+		config[path] = strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		config[path] = strconv.FormatBool(v)
+	case nil:
+		config[path] = "null"
+	default:
+		config[path] = fmt.Sprint(v)
+	}
+}
@@ -3,8 +3,10 @@ package objectprotection
 import (
 	"log"
 	"strconv"
+	"strings"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rubrikinc/rubrik-client-for-prometheus/src/golang/util"
 	"github.com/rubrikinc/rubrik-sdk-for-go/rubrikcdm"
 )
 
@@ -36,10 +38,116 @@ var (
 			"yearlyRetention",
 		},
 	)
+	// Rubrik SLA Domain model
+	slaDomainInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_sla_domain_info",
+			Help: "Information for an SLA domain",
+		},
+		[]string{
+			"primaryClusterId",
+			"slaDomainName",
+			"slaDomainId",
+		},
+	)
+	slaDomainFrequency = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_sla_domain_frequency",
+			Help: "Snapshot frequency of an SLA domain tier, in units of the tier (hours for hourly, days for daily and so on)",
+		},
+		[]string{
+			"primaryClusterId",
+			"slaDomainName",
+			"slaDomainId",
+			"tier",
+		},
+	)
+	slaDomainRetention = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_sla_domain_retention",
+			Help: "Snapshot retention of an SLA domain tier, in units of the tier (hours for hourly, days for daily and so on)",
+		},
+		[]string{
+			"primaryClusterId",
+			"slaDomainName",
+			"slaDomainId",
+			"tier",
+		},
+	)
+	slaDomainMaxLocalRetention = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_sla_domain_max_local_retention_seconds",
+			Help: "Maximum local retention of an SLA domain",
+		},
+		[]string{
+			"primaryClusterId",
+			"slaDomainName",
+			"slaDomainId",
+		},
+	)
+	slaDomainArchivalThreshold = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_sla_domain_archival_threshold_seconds",
+			Help: "Age at which snapshots of an SLA domain are archived to an archival location",
+		},
+		[]string{
+			"primaryClusterId",
+			"slaDomainName",
+			"slaDomainId",
+			"archivalLocationName",
+			"archivalLocationId",
+		},
+	)
+	slaDomainInstantArchive = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_sla_domain_instant_archive",
+			Help: "Whether an SLA domain archives snapshots as soon as they are taken (1 is enabled, 0 is disabled)",
+		},
+		[]string{
+			"primaryClusterId",
+			"slaDomainName",
+			"slaDomainId",
+			"archivalLocationName",
+			"archivalLocationId",
+		},
+	)
+	slaDomainReplicationRetention = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_sla_domain_replication_retention_seconds",
+			Help: "Retention of snapshots of an SLA domain on a replication target",
+		},
+		[]string{
+			"primaryClusterId",
+			"slaDomainName",
+			"slaDomainId",
+			"replicationTargetName",
+			"replicationTargetId",
+		},
+	)
+	slaDomainObjectCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_sla_domain_object_count",
+			Help: "Number of objects assigned to an SLA domain",
+		},
+		[]string{
+			"primaryClusterId",
+			"slaDomainName",
+			"slaDomainId",
+			"objectType",
+		},
+	)
 )
 
 func init() {
 	prometheus.MustRegister(slaDomainSummary)
+	prometheus.MustRegister(slaDomainInfo)
+	prometheus.MustRegister(slaDomainFrequency)
+	prometheus.MustRegister(slaDomainRetention)
+	prometheus.MustRegister(slaDomainMaxLocalRetention)
+	prometheus.MustRegister(slaDomainArchivalThreshold)
+	prometheus.MustRegister(slaDomainInstantArchive)
+	prometheus.MustRegister(slaDomainReplicationRetention)
+	prometheus.MustRegister(slaDomainObjectCount)
 }
 
 // GetSlaDomainSummary ...
@@ -52,6 +160,8 @@ func GetSlaDomainSummary(rubrik *rubrikcdm.Credentials, clusterName string) {
 	}
 
 	slaEntities := slaData.(map[string]interface{})["data"].([]interface{})
+	definitions := map[string]slaDefinition{}
+	var models []slaDomainModel
 
 	for v := range slaEntities {
 		thisClusterId, thisSlaDomainName, thisSlaDomainId := "null", "null", "null"
@@ -99,6 +209,9 @@ func GetSlaDomainSummary(rubrik *rubrikcdm.Credentials, clusterName string) {
 			thisYearlyFrequency = thisFrequencies.(map[string]interface{})["yearly"].(map[string]interface{})["frequency"].(float64)
 			thisYearlyRetention = thisFrequencies.(map[string]interface{})["yearly"].(map[string]interface{})["retention"].(float64)
 		}
+		definitions[thisSlaDomainName] = newSlaDefinition(thisSlaDomainId, thisSlaDomainName, thisFrequencies)
+		models = append(models, slaDomainModel{thisClusterId, thisSlaDomainName, thisSlaDomainId, slaEntities[v].(map[string]interface{})})
+		trackSlaDomainChanges(thisClusterId, thisSlaDomainName, thisSlaDomainId, slaEntities[v].(map[string]interface{}))
 
 		slaDomainSummary.WithLabelValues(
 			thisClusterId,
@@ -121,4 +234,82 @@ func GetSlaDomainSummary(rubrik *rubrikcdm.Credentials, clusterName string) {
 			strconv.FormatFloat(thisYearlyRetention, 'f', -1, 64),
 		).Set(0)
 	}
+	util.ResetGauges(
+		slaDomainInfo,
+		slaDomainFrequency,
+		slaDomainRetention,
+		slaDomainMaxLocalRetention,
+		slaDomainArchivalThreshold,
+		slaDomainInstantArchive,
+		slaDomainReplicationRetention,
+		slaDomainObjectCount)
+	for _, model := range models {
+		setSlaDomainModel(model.clusterID, model.slaDomainName, model.slaDomainID, model.sla)
+	}
+	setSlaDefinitions(definitions)
+	saveSlaDomainChanges()
+}
+
+// slaDomainModel is an SLA domain read by GetSlaDomainSummary, kept until every SLA
+// domain has been read.
+type slaDomainModel struct {
+	clusterID     string
+	slaDomainName string
+	slaDomainID   string
+	sla           map[string]interface{}
+}
+
+// setSlaDomainModel exports every frequency tier, archival specification, replication
+// specification and object count of an SLA domain as numeric series.
+func setSlaDomainModel(clusterID, slaDomainName, slaDomainID string, sla map[string]interface{}) {
+	slaDomainInfo.WithLabelValues(clusterID, slaDomainName, slaDomainID).Set(1)
+	if maxLocalRetention, ok := sla["maxLocalRetentionLimit"].(float64); ok {
+		slaDomainMaxLocalRetention.WithLabelValues(clusterID, slaDomainName, slaDomainID).Set(maxLocalRetention)
+	}
+	frequencies, _ := sla["frequencies"].(map[string]interface{})
+	for tier, v := range frequencies {
+		thisTier, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if frequency, ok := thisTier["frequency"].(float64); ok {
+			slaDomainFrequency.WithLabelValues(clusterID, slaDomainName, slaDomainID, tier).Set(frequency)
+		}
+		if retention, ok := thisTier["retention"].(float64); ok {
+			slaDomainRetention.WithLabelValues(clusterID, slaDomainName, slaDomainID, tier).Set(retention)
+		}
+	}
+	archivalSpecs, _ := sla["archivalSpecs"].([]interface{})
+	for _, v := range archivalSpecs {
+		thisSpec := v.(map[string]interface{})
+		thisLocationName := util.ObjectString(thisSpec, "locationName")
+		thisLocationID := util.ObjectString(thisSpec, "locationId")
+		thisThreshold, _ := thisSpec["archivalThreshold"].(float64)
+		slaDomainArchivalThreshold.WithLabelValues(clusterID, slaDomainName, slaDomainID, thisLocationName, thisLocationID).Set(thisThreshold)
+		// instant archive is stored as an archival threshold of one second
+		instantArchive := 0.0
+		if thisThreshold == 1 {
+			instantArchive = 1
+		}
+		slaDomainInstantArchive.WithLabelValues(clusterID, slaDomainName, slaDomainID, thisLocationName, thisLocationID).Set(instantArchive)
+	}
+	replicationSpecs, _ := sla["replicationSpecs"].([]interface{})
+	for _, v := range replicationSpecs {
+		thisSpec := v.(map[string]interface{})
+		thisRetention, _ := thisSpec["retentionLimit"].(float64)
+		slaDomainReplicationRetention.WithLabelValues(
+			clusterID,
+			slaDomainName,
+			slaDomainID,
+			util.ObjectString(thisSpec, "locationName"),
+			util.ObjectString(thisSpec, "locationId")).Set(thisRetention)
+	}
+	// object counts are returned as numVms, numDbs, numFilesets and so on
+	for key, v := range sla {
+		count, ok := v.(float64)
+		if !ok || !strings.HasPrefix(key, "num") {
+			continue
+		}
+		slaDomainObjectCount.WithLabelValues(clusterID, slaDomainName, slaDomainID, strings.TrimPrefix(key, "num")).Set(count)
+	}
 }
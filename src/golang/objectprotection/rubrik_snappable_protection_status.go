@@ -4,6 +4,7 @@ import (
 	"log"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rubrikinc/rubrik-client-for-prometheus/src/golang/util"
 	"github.com/rubrikinc/rubrik-sdk-for-go/rubrikcdm"
 )
 
@@ -23,15 +24,58 @@ var (
 			"slaDomain",
 		},
 	)
+	// Rubrik Snappable expected snapshot interval and retention, from the SLA domain definitions
+	snappableExpectedSnapshotInterval = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_snappable_expected_snapshot_interval_seconds",
+			Help: "Longest time allowed between snapshots of snappables by their effective SLA domain",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectType",
+			"objectID",
+			"location",
+			"slaDomain",
+		},
+	)
+	snappableMaxRetention = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_snappable_max_retention_seconds",
+			Help: "Longest retention of snapshots of snappables by their effective SLA domain",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectType",
+			"objectID",
+			"location",
+			"slaDomain",
+		},
+	)
 )
 
 func init() {
 	// VMware vSphere VM effective sla domain
 	prometheus.MustRegister(snappableEffectiveSlaDomain)
+	prometheus.MustRegister(snappableExpectedSnapshotInterval)
+	prometheus.MustRegister(snappableMaxRetention)
+}
+
+// snappableKey identifies one snappable in the snappable SLA domain metrics.
+type snappableKey struct {
+	objectName string
+	objectType string
+	objectID   string
+	location   string
+	slaDomain  string
 }
 
 // GetSnappableEffectiveSlaDomain ...
 func GetSnappableEffectiveSlaDomain(rubrik *rubrikcdm.Credentials, clusterName string) {
+	if err := loadSlaDefinitions(rubrik); err != nil {
+		log.Println("Error from objectprotection.GetSnappableEffectiveSlaDomain: ", err)
+	}
 	reportData, err := rubrik.Get("internal", "/report?report_template=ObjectProtectionSummary&report_type=Canned", 60) // get our object protection summary report
 	if err != nil {
 		log.Printf("Error from objectprotection.GetSnappableEffectiveSlaDomain: ", err)
@@ -39,6 +83,7 @@ func GetSnappableEffectiveSlaDomain(rubrik *rubrikcdm.Credentials, clusterName s
 	}
 	reports := reportData.(map[string]interface{})["data"].([]interface{})
 	reportID := reports[0].(map[string]interface{})["id"]
+	var snappables []snappableKey
 	body := map[string]interface{}{
 		"limit": 100,
 	}
@@ -72,16 +117,17 @@ func GetSnappableEffectiveSlaDomain(rubrik *rubrikcdm.Credentials, clusterName s
 					thisSlaDomain = v.([]interface{})[i].(string)
 				}
 			}
-			snappableEffectiveSlaDomain.WithLabelValues(
+			snappables = append(snappables, snappableKey{thisObjectName, thisObjectType, thisObjectID, thisLocation, thisSlaDomain})
+			trackSlaAssignmentChanges(
 				clusterName,
 				thisObjectName,
 				thisObjectType,
 				thisObjectID,
 				thisLocation,
-				thisSlaDomain).Set(0)
+				thisSlaDomain)
 		}
 		if !hasMore {
-			return
+			break
 		} else {
 			body = map[string]interface{}{
 				"limit":  1000,
@@ -89,4 +135,36 @@ func GetSnappableEffectiveSlaDomain(rubrik *rubrikcdm.Credentials, clusterName s
 			}
 		}
 	}
+	saveSlaAssignmentChanges()
+	util.ResetGauges(
+		snappableEffectiveSlaDomain,
+		snappableExpectedSnapshotInterval,
+		snappableMaxRetention)
+	for _, k := range snappables {
+		snappableEffectiveSlaDomain.WithLabelValues(
+			clusterName,
+			k.objectName,
+			k.objectType,
+			k.objectID,
+			k.location,
+			k.slaDomain).Set(0)
+		definition, ok := getSlaDefinition(k.slaDomain)
+		if !ok {
+			continue
+		}
+		snappableExpectedSnapshotInterval.WithLabelValues(
+			clusterName,
+			k.objectName,
+			k.objectType,
+			k.objectID,
+			k.location,
+			k.slaDomain).Set(definition.expectedSnapshotInterval().Seconds())
+		snappableMaxRetention.WithLabelValues(
+			clusterName,
+			k.objectName,
+			k.objectType,
+			k.objectID,
+			k.location,
+			k.slaDomain).Set(definition.maxRetention().Seconds())
+	}
 }
@@ -0,0 +1,109 @@
+package objectprotection
+
+import (
+	"log"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// Rubrik Snappable slaDomain assignment changes
+	snappableSlaAssignmentChanges = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rubrik_snappable_sla_assignment_changes_total",
+			Help: "Number of changes seen to the effective slaDomain of snappables",
+		},
+		[]string{
+			"clusterName",
+			"objectType",
+			"fromSlaDomain",
+			"toSlaDomain",
+		},
+	)
+	snappableSlaAssignmentLastChange = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_snappable_sla_assignment_last_change_timestamp_seconds",
+			Help: "Time the effective slaDomain of a snappable was last seen to change",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectType",
+			"objectID",
+			"location",
+		},
+	)
+)
+
+// Snappable slaDomain assignment state, set by ConfigureSlaAssignmentTracking
+var (
+	slaAssignmentStateFile string
+	slaAssignments         = map[string]slaAssignment{}
+	slaAssignmentsChanged  bool
+)
+
+func init() {
+	prometheus.MustRegister(snappableSlaAssignmentChanges)
+	prometheus.MustRegister(snappableSlaAssignmentLastChange)
+}
+
+// slaAssignment is the last seen effective slaDomain of a snappable.
+type slaAssignment struct {
+	SlaDomain  string `json:"slaDomain"`
+	LastChange int64  `json:"lastChange,omitempty"`
+}
+
+// ConfigureSlaAssignmentTracking persists the effective slaDomain of every snappable
+// seen by GetSnappableEffectiveSlaDomain to stateFile, so that assignment changes made
+// while the agent is not running are still counted.
+func ConfigureSlaAssignmentTracking(stateFile string) error {
+	if stateFile == "" {
+		return nil
+	}
+	if err := loadStateFile(stateFile, &slaAssignments); err != nil {
+		return err
+	}
+	slaAssignmentStateFile = stateFile
+	return nil
+}
+
+// trackSlaAssignmentChanges compares the effective slaDomain of a snappable with the
+// last one seen.
+func trackSlaAssignmentChanges(clusterName, objectName, objectType, objectID, location, slaDomain string) {
+	previousAssignment, seen := slaAssignments[objectID]
+	if seen && previousAssignment.SlaDomain != slaDomain {
+		snappableSlaAssignmentChanges.WithLabelValues(
+			clusterName,
+			objectType,
+			previousAssignment.SlaDomain,
+			slaDomain).Inc()
+		previousAssignment.LastChange = time.Now().Unix()
+		slaAssignmentsChanged = true
+	}
+	if !seen {
+		slaAssignmentsChanged = true
+	}
+	slaAssignments[objectID] = slaAssignment{SlaDomain: slaDomain, LastChange: previousAssignment.LastChange}
+	if previousAssignment.LastChange != 0 {
+		snappableSlaAssignmentLastChange.WithLabelValues(
+			clusterName,
+			objectName,
+			objectType,
+			objectID,
+			location).Set(float64(previousAssignment.LastChange))
+	}
+}
+
+// saveSlaAssignmentChanges writes the snappable slaDomain assignments to the state file
+// when they have changed.
+func saveSlaAssignmentChanges() {
+	if slaAssignmentStateFile == "" || !slaAssignmentsChanged {
+		return
+	}
+	if err := saveStateFile(slaAssignmentStateFile, slaAssignments); err != nil {
+		log.Println("Error from objectprotection.GetSnappableEffectiveSlaDomain: ", err)
+		return
+	}
+	slaAssignmentsChanged = false
+}
@@ -0,0 +1,219 @@
+package objectprotection
+
+import (
+	"log"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rubrikinc/rubrik-client-for-prometheus/src/golang/util"
+	"github.com/rubrikinc/rubrik-sdk-for-go/rubrikcdm"
+)
+
+// discoveredObjectType describes where to list the discovered objects of one type and
+// how to find their location.
+type discoveredObjectType struct {
+	objectType string
+	apiVersion string
+	endpoint   string
+	location   func(object map[string]interface{}) string
+}
+
+// discoveredObjectTypes are the object types checked for missing protection.
+var discoveredObjectTypes = []discoveredObjectType{
+	{
+		objectType: "VmwareVirtualMachine",
+		apiVersion: "v1",
+		endpoint:   "/vmware/vm?is_relic=false",
+		location: func(object map[string]interface{}) string {
+			return util.ObjectString(object, "hostName")
+		},
+	},
+	{
+		objectType: "Mssql",
+		apiVersion: "v1",
+		endpoint:   "/mssql/db?is_relic=false",
+		location: func(object map[string]interface{}) string {
+			rootProperties, _ := object["rootProperties"].(map[string]interface{})
+			return util.ObjectString(rootProperties, "rootName") + "\\" + util.ObjectString(object, "instanceName")
+		},
+	},
+	{
+		objectType: "OracleDatabase",
+		apiVersion: "internal",
+		endpoint:   "/oracle/db?is_relic=false",
+		location: func(object map[string]interface{}) string {
+			if racName, ok := object["racName"].(string); ok && racName != "" {
+				return racName
+			}
+			return util.ObjectString(object, "standaloneHostName")
+		},
+	},
+	{
+		objectType: "Fileset",
+		apiVersion: "v1",
+		endpoint:   "/fileset?is_relic=false",
+		location: func(object map[string]interface{}) string {
+			return util.ObjectString(object, "hostName")
+		},
+	},
+}
+
+var (
+	// Rubrik unprotected objects
+	unprotectedObjectCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_unprotected_object_count",
+			Help: "Number of discovered objects without an SLA domain.",
+		},
+		[]string{
+			"clusterName",
+			"objectType",
+			"location",
+			"reason",
+		},
+	)
+	unprotectedObjectInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_unprotected_object_info",
+			Help: "Information for a discovered object without an SLA domain.",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectType",
+			"objectID",
+			"location",
+			"reason",
+		},
+	)
+)
+
+func init() {
+	// unprotected objects
+	prometheus.MustRegister(unprotectedObjectCount)
+	prometheus.MustRegister(unprotectedObjectInfo)
+}
+
+// unprotectedObject is a discovered object which Rubrik does not protect.
+type unprotectedObject struct {
+	objectName string
+	objectType string
+	objectID   string
+	location   string
+	reason     string
+}
+
+// unprotectedObjectKey identifies one rubrik_unprotected_object_count series.
+type unprotectedObjectKey struct {
+	objectType string
+	location   string
+	reason     string
+}
+
+// GetUnprotectedObjects ...
+func GetUnprotectedObjects(rubrik *rubrikcdm.Credentials, clusterName string) {
+	var objects []unprotectedObject
+	// hosts with a fileset that has an SLA domain
+	protectedFilesetHosts := map[string]bool{}
+	for _, objectType := range discoveredObjectTypes {
+		discovered, err := getDiscoveredObjects(rubrik, objectType.apiVersion, objectType.endpoint)
+		if err != nil {
+			log.Println("Error from objectprotection.GetUnprotectedObjects: ", err)
+			return
+		}
+		for _, thisObject := range discovered {
+			reason := unprotectedReason(thisObject)
+			if reason == "" {
+				if objectType.objectType == "Fileset" {
+					protectedFilesetHosts[util.ObjectString(thisObject, "hostId")] = true
+				}
+				continue
+			}
+			objects = append(objects, unprotectedObject{
+				objectName: util.ObjectString(thisObject, "name"),
+				objectType: objectType.objectType,
+				objectID:   util.ObjectString(thisObject, "id"),
+				location:   objectType.location(thisObject),
+				reason:     reason,
+			})
+		}
+	}
+	// a host without any protected fileset has files that no backup covers
+	hosts, err := getDiscoveredObjects(rubrik, "v1", "/host?primary_cluster_id=local")
+	if err != nil {
+		log.Println("Error from objectprotection.GetUnprotectedObjects: ", err)
+		return
+	}
+	for _, thisHost := range hosts {
+		hostID := util.ObjectString(thisHost, "id")
+		if protectedFilesetHosts[hostID] {
+			continue
+		}
+		hostName := util.ObjectString(thisHost, "hostname", "name")
+		objects = append(objects, unprotectedObject{
+			objectName: hostName,
+			objectType: "Host",
+			objectID:   hostID,
+			location:   hostName,
+			reason:     "NoProtectedFileset",
+		})
+	}
+	util.ResetGauges(unprotectedObjectCount, unprotectedObjectInfo)
+	counts := map[unprotectedObjectKey]float64{}
+	for _, o := range objects {
+		counts[unprotectedObjectKey{o.objectType, o.location, o.reason}]++
+		unprotectedObjectInfo.WithLabelValues(
+			clusterName,
+			o.objectName,
+			o.objectType,
+			o.objectID,
+			o.location,
+			o.reason).Set(1)
+	}
+	for k, count := range counts {
+		unprotectedObjectCount.WithLabelValues(
+			clusterName,
+			k.objectType,
+			k.location,
+			k.reason).Set(count)
+	}
+}
+
+// getDiscoveredObjects returns every object listed by a paged endpoint, which must
+// already have a query string.
+func getDiscoveredObjects(rubrik *rubrikcdm.Credentials, apiVersion, endpoint string) ([]map[string]interface{}, error) {
+	var objects []map[string]interface{}
+	offset := 0
+	for {
+		objectData, err := rubrik.Get(apiVersion, endpoint+"&limit=500&offset="+strconv.Itoa(offset), 60)
+		if err != nil {
+			return nil, err
+		}
+		data, _ := objectData.(map[string]interface{})["data"].([]interface{})
+		for _, v := range data {
+			if thisObject, ok := v.(map[string]interface{}); ok {
+				objects = append(objects, thisObject)
+			}
+		}
+		offset += len(data)
+		hasMore, _ := objectData.(map[string]interface{})["hasMore"].(bool)
+		if !hasMore || len(data) == 0 {
+			return objects, nil
+		}
+	}
+}
+
+// unprotectedReason returns why an object is not protected, or "" when it has an SLA domain.
+func unprotectedReason(object map[string]interface{}) string {
+	slaDomainID, _ := object["effectiveSlaDomainId"].(string)
+	slaDomainName, _ := object["effectiveSlaDomainName"].(string)
+	switch {
+	case slaDomainID == "":
+		return "NoSla"
+	case slaDomainID == "UNPROTECTED":
+		return "Unprotected"
+	case slaDomainID == "DO_NOT_PROTECT" || slaDomainName == "Do Not Protect":
+		return "DoNotProtect"
+	}
+	return ""
+}
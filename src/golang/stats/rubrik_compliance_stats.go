@@ -2,8 +2,11 @@ package stats
 
 import (
 	"log"
-	"github.com/rubrikinc/rubrik-sdk-for-go/rubrikcdm"
+	"strconv"
+
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rubrikinc/rubrik-client-for-prometheus/src/golang/util"
+	"github.com/rubrikinc/rubrik-sdk-for-go/rubrikcdm"
 )
 
 var (
@@ -26,25 +29,101 @@ var (
 			"clusterName",
 		},
 	)
+	// per object compliance stats
+	rubrikObjectSLACompliance = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_object_sla_compliance",
+			Help: "SLA compliance of object in Rubrik cluster (1 is in compliance, 0 is out of compliance).",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectType",
+			"objectID",
+			"location",
+			"slaDomain",
+		},
+	)
+	rubrikObjectMissedSnapshots = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_object_missed_snapshots",
+			Help: "Number of snapshots missed by object in Rubrik cluster.",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectType",
+			"objectID",
+			"location",
+			"slaDomain",
+		},
+	)
+	// per SLA domain compliance stats
+	rubrikSLADomainCompliantCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_sla_domain_compliant_object_count",
+			Help: "Number of SLA compliant objects in Rubrik cluster by SLA domain and object type.",
+		},
+		[]string{
+			"clusterName",
+			"slaDomain",
+			"objectType",
+		},
+	)
+	rubrikSLADomainNonCompliantCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_sla_domain_non_compliant_object_count",
+			Help: "Number of non-SLA compliant objects in Rubrik cluster by SLA domain and object type.",
+		},
+		[]string{
+			"clusterName",
+			"slaDomain",
+			"objectType",
+		},
+	)
 )
 
+// objectCompliance is one row of the SLA compliance summary report table.
+type objectCompliance struct {
+	objectName      string
+	objectType      string
+	objectID        string
+	location        string
+	slaDomain       string
+	inCompliance    bool
+	knownCompliance bool
+	missedSnapshots float64
+}
+
+// slaComplianceKey identifies one series of the per SLA domain compliance stats.
+type slaComplianceKey struct {
+	slaDomain  string
+	objectType string
+}
+
 func init() {
 	// compliance stats
 	prometheus.MustRegister(rubrikSLACompliantCount)
 	prometheus.MustRegister(rubrikSLANonCompliantCount)
+	// per object and per SLA domain compliance stats
+	prometheus.MustRegister(rubrikObjectSLACompliance)
+	prometheus.MustRegister(rubrikObjectMissedSnapshots)
+	prometheus.MustRegister(rubrikSLADomainCompliantCount)
+	prometheus.MustRegister(rubrikSLADomainNonCompliantCount)
 }
 
-// Get ...
+// GetSlaComplianceStats ...
 func GetSlaComplianceStats(rubrik *rubrikcdm.Credentials, clusterName string) {
-	reportData,err := rubrik.Get("internal","/report?report_template=SlaComplianceSummary&report_type=Canned", 60) // get our sla compliance summary report
+	reportData, err := rubrik.Get("internal", "/report?report_template=SlaComplianceSummary&report_type=Canned", 60) // get our sla compliance summary report
 	if err != nil {
-		log.Printf("Error from stats.GetSlaComplianceStats: ",err)
+		log.Println("Error from stats.GetSlaComplianceStats: ", err)
+		return
 	}
 	reports := reportData.(map[string]interface{})["data"].([]interface{})
 	reportID := reports[0].(map[string]interface{})["id"]
-	chartData,err := rubrik.Get("internal","/report/"+reportID.(string)+"/chart?chart_id=chart0") // get our chart for the report
+	chartData, err := rubrik.Get("internal", "/report/"+reportID.(string)+"/chart?chart_id=chart0") // get our chart for the report
 	if err != nil {
-		log.Printf("Error from stats.GetSlaComplianceStats: ",err)
+		log.Println("Error from stats.GetSlaComplianceStats: ", err)
 		return
 	}
 	for _, v := range chartData.([]interface{}) {
@@ -61,4 +140,104 @@ func GetSlaComplianceStats(rubrik *rubrikcdm.Credentials, clusterName string) {
 			}
 		}
 	}
-}
\ No newline at end of file
+	// read the compliance of every object from the report table
+	var objects []objectCompliance
+	body := map[string]interface{}{
+		"limit": 100,
+	}
+	for {
+		tableData, err := rubrik.Post("internal", "/report/"+reportID.(string)+"/table", body, 60) // get our first page of data for the report
+		if err != nil {
+			log.Println("Error from stats.GetSlaComplianceStats: ", err)
+			return
+		}
+		dataGrid := tableData.(map[string]interface{})["dataGrid"].([]interface{})
+		hasMore := tableData.(map[string]interface{})["hasMore"].(bool)
+		cursor := tableData.(map[string]interface{})["cursor"]
+		columns := tableData.(map[string]interface{})["columns"].([]interface{})
+		for _, v := range dataGrid {
+			thisObject := objectCompliance{
+				objectName: "null",
+				objectType: "null",
+				objectID:   "null",
+				location:   "null",
+				slaDomain:  "null",
+			}
+			for i := 0; i < len(columns); i++ {
+				value, ok := v.([]interface{})[i].(string)
+				if !ok {
+					continue
+				}
+				switch columns[i] {
+				case "ObjectId", "ObjectLinkingId":
+					thisObject.objectID = value
+				case "ObjectName":
+					thisObject.objectName = value
+				case "ObjectType":
+					thisObject.objectType = value
+				case "Location":
+					thisObject.location = value
+				case "SlaDomain":
+					thisObject.slaDomain = value
+				case "ComplianceStatus":
+					thisObject.inCompliance = value == "InCompliance"
+					thisObject.knownCompliance = value == "InCompliance" || value == "NonCompliance"
+				case "MissedSnapshots", "MissedLocalSnapshots":
+					thisObject.missedSnapshots, _ = strconv.ParseFloat(value, 64)
+				}
+			}
+			objects = append(objects, thisObject)
+		}
+		if !hasMore {
+			break
+		}
+		body = map[string]interface{}{
+			"limit":  1000,
+			"cursor": cursor,
+		}
+	}
+	util.ResetGauges(
+		rubrikObjectSLACompliance,
+		rubrikObjectMissedSnapshots,
+		rubrikSLADomainCompliantCount,
+		rubrikSLADomainNonCompliantCount)
+	compliantCounts := map[slaComplianceKey]float64{}
+	nonCompliantCounts := map[slaComplianceKey]float64{}
+	for _, o := range objects {
+		rubrikObjectMissedSnapshots.WithLabelValues(
+			clusterName,
+			o.objectName,
+			o.objectType,
+			o.objectID,
+			o.location,
+			o.slaDomain).Set(o.missedSnapshots)
+		// objects without a known compliance status are neither compliant nor non-compliant
+		if !o.knownCompliance {
+			continue
+		}
+		compliance := 0.0
+		thisSla := slaComplianceKey{o.slaDomain, o.objectType}
+		if o.inCompliance {
+			compliance = 1
+			compliantCounts[thisSla]++
+		} else {
+			nonCompliantCounts[thisSla]++
+		}
+		rubrikObjectSLACompliance.WithLabelValues(
+			clusterName,
+			o.objectName,
+			o.objectType,
+			o.objectID,
+			o.location,
+			o.slaDomain).Set(compliance)
+	}
+	for k := range compliantCounts {
+		if _, ok := nonCompliantCounts[k]; !ok {
+			nonCompliantCounts[k] = 0
+		}
+	}
+	for k := range nonCompliantCounts {
+		rubrikSLADomainCompliantCount.WithLabelValues(clusterName, k.slaDomain, k.objectType).Set(compliantCounts[k])
+		rubrikSLADomainNonCompliantCount.WithLabelValues(clusterName, k.slaDomain, k.objectType).Set(nonCompliantCounts[k])
+	}
+}
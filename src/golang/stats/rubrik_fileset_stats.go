@@ -0,0 +1,376 @@
+package stats
+
+import (
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rubrikinc/rubrik-client-for-prometheus/src/golang/util"
+	"github.com/rubrikinc/rubrik-sdk-for-go/rubrikcdm"
+)
+
+// filesetObjectTypes are the report object types of Linux, Windows and NAS filesets.
+var filesetObjectTypes = []string{
+	"LinuxFileset",
+	"WindowsFileset",
+	"ShareFileset",
+}
+
+var (
+	// fileset storage stats
+	rubrikFilesetCapacityLocalUsed = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_fileset_capacity_local_used_bytes",
+			Help: "Local storage consumption for fileset snapshots.",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectID",
+			"objectType",
+			"location",
+		},
+	)
+	rubrikFilesetCapacityArchiveUsed = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_fileset_capacity_archive_used_bytes",
+			Help: "Archive storage consumption for fileset snapshots.",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectID",
+			"objectType",
+			"location",
+		},
+	)
+	rubrikFilesetLastSnapshot = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_fileset_last_snapshot_timestamp_seconds",
+			Help: "Time of the latest local snapshot of fileset.",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectID",
+			"objectType",
+			"location",
+		},
+	)
+	// fileset backup stats
+	rubrikFilesetLastBackupFilesTransferred = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_fileset_last_backup_files_transferred",
+			Help: "Number of files transferred by the latest succeeded backup of fileset.",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectID",
+			"objectType",
+			"location",
+		},
+	)
+	rubrikFilesetLastBackupTransferred = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_fileset_last_backup_transferred_bytes",
+			Help: "Data transferred by the latest succeeded backup of fileset.",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectID",
+			"objectType",
+			"location",
+		},
+	)
+	// fileset state
+	rubrikFilesetInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_fileset_info",
+			Help: "Information for fileset, including its fileset template.",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectID",
+			"objectType",
+			"location",
+			"template",
+			"templateID",
+			"slaDomain",
+		},
+	)
+	rubrikFilesetHostStatus = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_fileset_host_status",
+			Help: "Connectivity of the host or NAS share of fileset to Rubrik cluster (1 is Connected, 0 is anything else).",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectID",
+			"objectType",
+			"location",
+			"status",
+		},
+	)
+)
+
+func init() {
+	// fileset storage stats
+	prometheus.MustRegister(rubrikFilesetCapacityLocalUsed)
+	prometheus.MustRegister(rubrikFilesetCapacityArchiveUsed)
+	prometheus.MustRegister(rubrikFilesetLastSnapshot)
+	// fileset backup stats
+	prometheus.MustRegister(rubrikFilesetLastBackupFilesTransferred)
+	prometheus.MustRegister(rubrikFilesetLastBackupTransferred)
+	// fileset state
+	prometheus.MustRegister(rubrikFilesetInfo)
+	prometheus.MustRegister(rubrikFilesetHostStatus)
+}
+
+// filesetKey identifies one fileset in the report based fileset metrics.
+type filesetKey struct {
+	objectName string
+	objectID   string
+	objectType string
+	location   string
+}
+
+// filesetCapacity is the storage consumption and latest snapshot of one fileset.
+type filesetCapacity struct {
+	localStorage   float64
+	archiveStorage float64
+	lastSnapshot   time.Time
+}
+
+// filesetBackup is the latest succeeded backup of one fileset.
+type filesetBackup struct {
+	startTime        time.Time
+	filesTransferred float64
+	dataTransferred  float64
+}
+
+// latestFilesetBackups holds the latest succeeded backup of each fileset by fileset ID,
+// recorded by Get24HJobStats from the protection tasks report and exported by
+// GetFilesetStateStats.
+var (
+	latestFilesetBackups      = map[string]filesetBackup{}
+	latestFilesetBackupsMutex sync.Mutex
+)
+
+// isFilesetObjectType returns true for the report object types of filesets.
+func isFilesetObjectType(objectType string) bool {
+	for _, filesetObjectType := range filesetObjectTypes {
+		if objectType == filesetObjectType {
+			return true
+		}
+	}
+	return false
+}
+
+// recordFilesetBackup keeps backup as the latest succeeded backup of a fileset, unless
+// a later one has already been recorded.
+func recordFilesetBackup(objectID string, backup filesetBackup) {
+	latestFilesetBackupsMutex.Lock()
+	defer latestFilesetBackupsMutex.Unlock()
+	if latest, ok := latestFilesetBackups[objectID]; ok && !backup.startTime.After(latest.startTime) {
+		return
+	}
+	latestFilesetBackups[objectID] = backup
+}
+
+// GetFilesetCapacityStats ...
+func GetFilesetCapacityStats(rubrik *rubrikcdm.Credentials, clusterName string) {
+	reportData, err := rubrik.Get("internal", "/report?report_template=ObjectProtectionSummary&report_type=Canned", 60) // get our object protection summary report
+	if err != nil {
+		log.Println("Error from stats.GetFilesetCapacityStats: ", err)
+		return
+	}
+	reports := reportData.(map[string]interface{})["data"].([]interface{})
+	reportID := reports[0].(map[string]interface{})["id"]
+	filesets := map[filesetKey]filesetCapacity{}
+	for _, objectType := range filesetObjectTypes {
+		body := map[string]interface{}{
+			"limit": 100,
+			"requestFilters": map[string]interface{}{
+				"objectType": objectType,
+			},
+		}
+		for {
+			tableData, err := rubrik.Post("internal", "/report/"+reportID.(string)+"/table", body, 60) // get our first page of data for the report
+			if err != nil {
+				log.Println("Error from stats.GetFilesetCapacityStats: ", err)
+				return
+			}
+			dataGrid := tableData.(map[string]interface{})["dataGrid"].([]interface{})
+			hasMore := tableData.(map[string]interface{})["hasMore"].(bool)
+			cursor := tableData.(map[string]interface{})["cursor"]
+			columns := tableData.(map[string]interface{})["columns"].([]interface{})
+			for _, v := range dataGrid {
+				thisFileset := filesetKey{"null", "null", objectType, "null"}
+				var thisCapacity filesetCapacity
+				for i := 0; i < len(columns); i++ {
+					value, ok := v.([]interface{})[i].(string)
+					if !ok {
+						continue
+					}
+					switch columns[i] {
+					case "ObjectId", "ObjectLinkingId":
+						thisFileset.objectID = value
+					case "ObjectName":
+						thisFileset.objectName = value
+					case "Location":
+						thisFileset.location = value
+					case "LocalStorage":
+						thisCapacity.localStorage, _ = strconv.ParseFloat(value, 64)
+					case "ArchiveStorage":
+						thisCapacity.archiveStorage, _ = strconv.ParseFloat(value, 64)
+					case "LatestLocalSnapshot", "LastSnapshot":
+						thisCapacity.lastSnapshot = util.ParseReportTime(value)
+					}
+				}
+				filesets[thisFileset] = thisCapacity
+			}
+			if !hasMore {
+				break
+			}
+			body = map[string]interface{}{
+				"limit":  1000,
+				"cursor": cursor,
+				"requestFilters": map[string]interface{}{
+					"objectType": objectType,
+				},
+			}
+		}
+	}
+	util.ResetGauges(
+		rubrikFilesetCapacityLocalUsed,
+		rubrikFilesetCapacityArchiveUsed,
+		rubrikFilesetLastSnapshot)
+	for k, capacity := range filesets {
+		rubrikFilesetCapacityLocalUsed.WithLabelValues(
+			clusterName,
+			k.objectName,
+			k.objectID,
+			k.objectType,
+			k.location).Set(capacity.localStorage)
+		rubrikFilesetCapacityArchiveUsed.WithLabelValues(
+			clusterName,
+			k.objectName,
+			k.objectID,
+			k.objectType,
+			k.location).Set(capacity.archiveStorage)
+		if !capacity.lastSnapshot.IsZero() {
+			rubrikFilesetLastSnapshot.WithLabelValues(
+				clusterName,
+				k.objectName,
+				k.objectID,
+				k.objectType,
+				k.location).Set(float64(capacity.lastSnapshot.Unix()))
+		}
+	}
+}
+
+// GetFilesetStateStats ...
+func GetFilesetStateStats(rubrik *rubrikcdm.Credentials, clusterName string) {
+	hosts, err := getHosts(rubrik)
+	if err != nil {
+		log.Println("Error from stats.GetFilesetStateStats: ", err)
+		return
+	}
+	shareData, err := getObjects(rubrik, "internal", "/host/share")
+	if err != nil {
+		log.Println("Error from stats.GetFilesetStateStats: ", err)
+		return
+	}
+	shares := map[string]rubrikHost{}
+	for _, thisShare := range shareData {
+		shares[util.ObjectString(thisShare, "id")] = rubrikHost{
+			name:   util.ObjectString(thisShare, "hostname") + ":" + util.ObjectString(thisShare, "exportPoint"),
+			status: util.ObjectString(thisShare, "status", "connectionStatus"),
+		}
+	}
+	filesets, err := getObjects(rubrik, "v1", "/fileset?is_relic=false")
+	if err != nil {
+		log.Println("Error from stats.GetFilesetStateStats: ", err)
+		return
+	}
+	listedFilesets := map[string]bool{}
+	for _, thisFileset := range filesets {
+		listedFilesets[util.ObjectString(thisFileset, "id")] = true
+	}
+	latestFilesetBackupsMutex.Lock()
+	defer latestFilesetBackupsMutex.Unlock()
+	for objectID := range latestFilesetBackups {
+		if !listedFilesets[objectID] {
+			delete(latestFilesetBackups, objectID)
+		}
+	}
+	util.ResetGauges(
+		rubrikFilesetInfo,
+		rubrikFilesetHostStatus,
+		rubrikFilesetLastBackupFilesTransferred,
+		rubrikFilesetLastBackupTransferred)
+	for _, thisFileset := range filesets {
+		objectName := util.ObjectString(thisFileset, "name")
+		objectID := util.ObjectString(thisFileset, "id")
+		location := util.ObjectString(thisFileset, "hostName")
+		var thisHost rubrikHost
+		var hasHost bool
+		objectType := "LinuxFileset"
+		if shareID, ok := thisFileset["shareId"].(string); ok && shareID != "" {
+			objectType = "ShareFileset"
+			thisHost, hasHost = shares[shareID]
+			if hasHost {
+				location = thisHost.name
+			}
+		} else {
+			if util.ObjectString(thisFileset, "operatingSystemType") == "Windows" {
+				objectType = "WindowsFileset"
+			}
+			thisHost, hasHost = hosts[util.ObjectString(thisFileset, "hostId")]
+		}
+		rubrikFilesetInfo.WithLabelValues(
+			clusterName,
+			objectName,
+			objectID,
+			objectType,
+			location,
+			util.ObjectString(thisFileset, "templateName"),
+			util.ObjectString(thisFileset, "templateId"),
+			util.ObjectString(thisFileset, "effectiveSlaDomainName")).Set(1)
+		if hasHost {
+			rubrikFilesetHostStatus.WithLabelValues(
+				clusterName,
+				objectName,
+				objectID,
+				objectType,
+				location,
+				thisHost.status).Set(thisHost.connected())
+		}
+		backup, ok := latestFilesetBackups[objectID]
+		if !ok {
+			continue
+		}
+		if backup.filesTransferred >= 0 {
+			rubrikFilesetLastBackupFilesTransferred.WithLabelValues(
+				clusterName,
+				objectName,
+				objectID,
+				objectType,
+				location).Set(backup.filesTransferred)
+		}
+		if backup.dataTransferred >= 0 {
+			rubrikFilesetLastBackupTransferred.WithLabelValues(
+				clusterName,
+				objectName,
+				objectID,
+				objectType,
+				location).Set(backup.dataTransferred)
+		}
+	}
+}
@@ -2,8 +2,12 @@ package stats
 
 import (
 	"log"
-	"github.com/rubrikinc/rubrik-sdk-for-go/rubrikcdm"
+	"strconv"
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rubrikinc/rubrik-client-for-prometheus/src/golang/util"
+	"github.com/rubrikinc/rubrik-sdk-for-go/rubrikcdm"
 )
 
 var (
@@ -35,27 +39,92 @@ var (
 			"clusterName",
 		},
 	)
+	rubrik24HJobs = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_24h_jobs",
+			Help: "Last 24 hours jobs in Rubrik cluster by object type, SLA domain, job type and status.",
+		},
+		[]string{
+			"clusterName",
+			"objectType",
+			"slaDomain",
+			"jobType",
+			"jobStatus",
+		},
+	)
+	// backup job duration and throughput
+	rubrikBackupJobDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "rubrik_backup_job_duration_seconds",
+			Help:    "Duration of succeeded Rubrik backup jobs.",
+			Buckets: prometheus.ExponentialBuckets(60, 2, 12),
+		},
+		[]string{
+			"clusterName",
+			"objectType",
+		},
+	)
+	rubrikBackupJobTransferred = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "rubrik_backup_job_transferred_bytes",
+			Help:    "Data transferred by succeeded Rubrik backup jobs.",
+			Buckets: prometheus.ExponentialBuckets(1048576, 4, 12),
+		},
+		[]string{
+			"clusterName",
+			"objectType",
+		},
+	)
+	rubrikBackupJobLastDuration = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_backup_job_last_duration_seconds",
+			Help: "Duration of the last observed succeeded Rubrik backup job for an object.",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectID",
+			"objectType",
+		},
+	)
 )
 
+// observedBackupJobs holds the backup jobs already added to the histograms, keyed by
+// object and start time, as the same job stays in the 24 hour report for a full day.
+var observedBackupJobs = map[string]time.Time{}
+
 func init() {
 	// job stats
 	prometheus.MustRegister(rubrik24HSucceededJobs)
 	prometheus.MustRegister(rubrik24HFailedJobs)
 	prometheus.MustRegister(rubrik24HCancelledJobs)
+	prometheus.MustRegister(rubrik24HJobs)
+	// backup job duration and throughput
+	prometheus.MustRegister(rubrikBackupJobDuration)
+	prometheus.MustRegister(rubrikBackupJobTransferred)
+	prometheus.MustRegister(rubrikBackupJobLastDuration)
+}
+
+// jobCountKey identifies one rubrik_24h_jobs series.
+type jobCountKey struct {
+	objectType string
+	slaDomain  string
+	jobType    string
+	jobStatus  string
 }
 
-// GetMssqlLiveMounts ...
+// Get24HJobStats ...
 func Get24HJobStats(rubrik *rubrikcdm.Credentials, clusterName string) {
-	reportData,err := rubrik.Get("internal","/report?report_template=ProtectionTasksDetails&report_type=Canned", 60) // get our protection tasks details report
+	reportData, err := rubrik.Get("internal", "/report?report_template=ProtectionTasksDetails&report_type=Canned", 60) // get our protection tasks details report
 	if err != nil {
-		log.Printf("Error from stats.Get24HJobStats: ",err)
+		log.Println("Error from stats.Get24HJobStats: ", err)
 		return
 	}
 	reports := reportData.(map[string]interface{})["data"].([]interface{})
 	reportID := reports[0].(map[string]interface{})["id"]
-	chartData,err := rubrik.Get("internal","/report/"+reportID.(string)+"/chart?chart_id=chart0", 60) // get our chart for the report
+	chartData, err := rubrik.Get("internal", "/report/"+reportID.(string)+"/chart?chart_id=chart0", 60) // get our chart for the report
 	if err != nil {
-		log.Printf("Error from stats.Get24HJobStats: ",err)
+		log.Println("Error from stats.Get24HJobStats: ", err)
 		return
 	}
 	for _, v := range chartData.([]interface{}) {
@@ -74,4 +143,113 @@ func Get24HJobStats(rubrik *rubrikcdm.Credentials, clusterName string) {
 			}
 		}
 	}
-}
\ No newline at end of file
+	// count the individual tasks in the report table, so that the totals above can be
+	// broken down by object type, SLA domain and job type
+	jobCounts := map[jobCountKey]float64{}
+	body := map[string]interface{}{
+		"limit": 100,
+	}
+	for {
+		tableData, err := rubrik.Post("internal", "/report/"+reportID.(string)+"/table", body, 60) // get our first page of data for the report
+		if err != nil {
+			log.Println("Error from stats.Get24HJobStats: ", err)
+			return
+		}
+		dataGrid := tableData.(map[string]interface{})["dataGrid"].([]interface{})
+		hasMore := tableData.(map[string]interface{})["hasMore"].(bool)
+		cursor := tableData.(map[string]interface{})["cursor"]
+		columns := tableData.(map[string]interface{})["columns"].([]interface{})
+		for _, v := range dataGrid {
+			thisJob := jobCountKey{"null", "null", "null", "null"}
+			thisObjectID, thisObjectName, thisStartTime := "null", "null", "null"
+			thisDuration, thisDataTransferred, thisFilesTransferred := -1.0, -1.0, -1.0
+			for i := 0; i < len(columns); i++ {
+				value, ok := v.([]interface{})[i].(string)
+				if !ok {
+					continue
+				}
+				switch columns[i] {
+				case "ObjectType":
+					thisJob.objectType = value
+				case "SlaDomain":
+					thisJob.slaDomain = value
+				case "TaskType":
+					thisJob.jobType = value
+				case "TaskStatus":
+					thisJob.jobStatus = value
+				case "ObjectId", "ObjectLinkingId":
+					thisObjectID = value
+				case "ObjectName":
+					thisObjectName = value
+				case "StartTime":
+					thisStartTime = value
+				case "Duration": // reported in milliseconds
+					if duration, err := strconv.ParseFloat(value, 64); err == nil {
+						thisDuration = duration / 1000
+					}
+				case "DataTransferred":
+					if transferred, err := strconv.ParseFloat(value, 64); err == nil {
+						thisDataTransferred = transferred
+					}
+				case "NumFilesTransferred":
+					if files, err := strconv.ParseFloat(value, 64); err == nil {
+						thisFilesTransferred = files
+					}
+				}
+			}
+			jobCounts[thisJob]++
+			if thisJob.jobType != "Backup" || thisJob.jobStatus != "Succeeded" {
+				continue
+			}
+			if isFilesetObjectType(thisJob.objectType) {
+				recordFilesetBackup(thisObjectID, filesetBackup{
+					startTime:        util.ParseReportTime(thisStartTime),
+					filesTransferred: thisFilesTransferred,
+					dataTransferred:  thisDataTransferred,
+				})
+			}
+			jobKey := thisObjectID + "|" + thisStartTime
+			if _, ok := observedBackupJobs[jobKey]; ok {
+				continue
+			}
+			observedBackupJobs[jobKey] = time.Now()
+			if thisDuration >= 0 {
+				rubrikBackupJobDuration.WithLabelValues(
+					clusterName,
+					thisJob.objectType).Observe(thisDuration)
+				rubrikBackupJobLastDuration.WithLabelValues(
+					clusterName,
+					thisObjectName,
+					thisObjectID,
+					thisJob.objectType).Set(thisDuration)
+			}
+			if thisDataTransferred >= 0 {
+				rubrikBackupJobTransferred.WithLabelValues(
+					clusterName,
+					thisJob.objectType).Observe(thisDataTransferred)
+			}
+		}
+		if !hasMore {
+			break
+		}
+		body = map[string]interface{}{
+			"limit":  1000,
+			"cursor": cursor,
+		}
+	}
+	// forget jobs that have dropped out of the 24 hour report
+	for k, seen := range observedBackupJobs {
+		if time.Since(seen) > 48*time.Hour {
+			delete(observedBackupJobs, k)
+		}
+	}
+	util.ResetGauges(rubrik24HJobs)
+	for k, count := range jobCounts {
+		rubrik24HJobs.WithLabelValues(
+			clusterName,
+			k.objectType,
+			k.slaDomain,
+			k.jobType,
+			k.jobStatus).Set(count)
+	}
+}
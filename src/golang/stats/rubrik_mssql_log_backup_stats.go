@@ -0,0 +1,185 @@
+package stats
+
+import (
+	"log"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rubrikinc/rubrik-client-for-prometheus/src/golang/util"
+	"github.com/rubrikinc/rubrik-sdk-for-go/rubrikcdm"
+)
+
+// logBackupRecoveryModels are the recovery models in which SQL Server takes transaction
+// log backups.
+var logBackupRecoveryModels = map[string]bool{
+	"FULL":        true,
+	"BULK_LOGGED": true,
+}
+
+var (
+	// SQL DB log backup stats
+	rubrikMssqlDbLastLogBackup = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_mssql_db_last_log_backup_timestamp_seconds",
+			Help: "Time of the latest transaction log backup of SQL DB.",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectID",
+			"location",
+		},
+	)
+	rubrikMssqlDbLogBackupFrequency = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_mssql_db_log_backup_frequency_seconds",
+			Help: "Configured transaction log backup frequency of SQL DB.",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectID",
+			"location",
+		},
+	)
+	rubrikMssqlDbLogBackupLag = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_mssql_db_log_backup_lag_seconds",
+			Help: "Time since the latest transaction log backup of SQL DB.",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectID",
+			"location",
+		},
+	)
+	rubrikMssqlDbLogBackupLagRatio = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_mssql_db_log_backup_lag_ratio",
+			Help: "Time since the latest transaction log backup of SQL DB divided by its log backup frequency.",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectID",
+			"location",
+		},
+	)
+)
+
+func init() {
+	// SQL DB log backup stats
+	prometheus.MustRegister(rubrikMssqlDbLastLogBackup)
+	prometheus.MustRegister(rubrikMssqlDbLogBackupFrequency)
+	prometheus.MustRegister(rubrikMssqlDbLogBackupLag)
+	prometheus.MustRegister(rubrikMssqlDbLogBackupLagRatio)
+}
+
+// mssqlLogBackup is the log backup state of one SQL DB.
+type mssqlLogBackup struct {
+	objectName    string
+	objectID      string
+	location      string
+	frequency     float64
+	lastLogBackup time.Time
+}
+
+// mssqlLogBackupDetails caches the last log backup state read from the details of
+// each SQL DB by DB ID, for DBs whose details cannot be read in a collection.
+var mssqlLogBackupDetails = map[string]mssqlLogBackup{}
+
+// GetMssqlLogBackupStats ...
+func GetMssqlLogBackupStats(rubrik *rubrikcdm.Credentials, clusterName string) {
+	dbData, err := getObjects(rubrik, "v1", "/mssql/db?is_relic=false&is_live_mount=false")
+	if err != nil {
+		log.Println("Error from stats.GetMssqlLogBackupStats: ", err)
+		return
+	}
+	var databases []mssqlLogBackup
+	listedDbs := map[string]bool{}
+	for _, thisDb := range dbData {
+		if !logBackupRecoveryModels[util.ObjectString(thisDb, "recoveryModel")] {
+			continue
+		}
+		rootProperties, _ := thisDb["rootProperties"].(map[string]interface{})
+		databases = append(databases, mssqlLogBackup{
+			objectName: util.ObjectString(thisDb, "name"),
+			objectID:   util.ObjectString(thisDb, "id"),
+			location:   util.ObjectString(rootProperties, "rootName") + "\\" + util.ObjectString(thisDb, "instanceName"),
+		})
+		listedDbs[util.ObjectString(thisDb, "id")] = true
+	}
+	for dbID := range mssqlLogBackupDetails {
+		if !listedDbs[dbID] {
+			delete(mssqlLogBackupDetails, dbID)
+		}
+	}
+	for i := range databases {
+		// the log backup frequency and latest recovery point are only in the DB details
+		// skip a DB whose details cannot be read, keeping its last known state
+		cached := mssqlLogBackupDetails[databases[i].objectID]
+		dbDetail, err := rubrik.Get("v1", "/mssql/db/"+databases[i].objectID, 60)
+		if err != nil {
+			log.Println("Error from stats.GetMssqlLogBackupStats: ", err)
+			databases[i].frequency, databases[i].lastLogBackup = cached.frequency, cached.lastLogBackup
+			continue
+		}
+		thisDb, _ := dbDetail.(map[string]interface{})
+		databases[i].frequency, _ = thisDb["logBackupFrequencyInSeconds"].(float64)
+		databases[i].lastLogBackup = util.ParseReportTime(util.ObjectString(thisDb, "latestRecoveryPoint"))
+		if databases[i].lastLogBackup.IsZero() {
+			// fall back to the end of the latest recoverable range
+			rangeData, err := rubrik.Get("v1", "/mssql/db/"+databases[i].objectID+"/recoverable_range", 60)
+			if err != nil {
+				log.Println("Error from stats.GetMssqlLogBackupStats: ", err)
+				databases[i].lastLogBackup = cached.lastLogBackup
+			} else {
+				ranges, _ := rangeData.(map[string]interface{})["data"].([]interface{})
+				for _, w := range ranges {
+					thisRange, _ := w.(map[string]interface{})
+					endTime := util.ParseReportTime(util.ObjectString(thisRange, "endTime"))
+					if endTime.After(databases[i].lastLogBackup) {
+						databases[i].lastLogBackup = endTime
+					}
+				}
+			}
+		}
+		mssqlLogBackupDetails[databases[i].objectID] = databases[i]
+	}
+	util.ResetGauges(
+		rubrikMssqlDbLastLogBackup,
+		rubrikMssqlDbLogBackupFrequency,
+		rubrikMssqlDbLogBackupLag,
+		rubrikMssqlDbLogBackupLagRatio)
+	for _, db := range databases {
+		if db.frequency > 0 {
+			rubrikMssqlDbLogBackupFrequency.WithLabelValues(
+				clusterName,
+				db.objectName,
+				db.objectID,
+				db.location).Set(db.frequency)
+		}
+		if db.lastLogBackup.IsZero() {
+			continue
+		}
+		lag := time.Since(db.lastLogBackup).Seconds()
+		rubrikMssqlDbLastLogBackup.WithLabelValues(
+			clusterName,
+			db.objectName,
+			db.objectID,
+			db.location).Set(float64(db.lastLogBackup.Unix()))
+		rubrikMssqlDbLogBackupLag.WithLabelValues(
+			clusterName,
+			db.objectName,
+			db.objectID,
+			db.location).Set(lag)
+		if db.frequency > 0 {
+			rubrikMssqlDbLogBackupLagRatio.WithLabelValues(
+				clusterName,
+				db.objectName,
+				db.objectID,
+				db.location).Set(lag / db.frequency)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package stats
+
+import (
+	"log"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rubrikinc/rubrik-client-for-prometheus/src/golang/util"
+	"github.com/rubrikinc/rubrik-sdk-for-go/rubrikcdm"
+)
+
+var (
+	// SQL Server host and instance state
+	rubrikMssqlHostStatus = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_mssql_host_status",
+			Help: "Connectivity of SQL Server host to Rubrik cluster (1 is Connected, 0 is anything else).",
+		},
+		[]string{
+			"clusterName",
+			"host",
+			"hostID",
+			"status",
+		},
+	)
+	rubrikMssqlInstanceStatus = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_mssql_instance_status",
+			Help: "Connectivity of the host of SQL Server instance to Rubrik cluster (1 is Connected, 0 is anything else).",
+		},
+		[]string{
+			"clusterName",
+			"instance",
+			"instanceID",
+			"host",
+		},
+	)
+	// SQL Server availability group state
+	rubrikMssqlAvailabilityGroupInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_mssql_availability_group_info",
+			Help: "Information for SQL Server availability group.",
+		},
+		[]string{
+			"clusterName",
+			"availabilityGroup",
+			"availabilityGroupID",
+			"backupPreference",
+		},
+	)
+	rubrikMssqlDbReplicaInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_mssql_db_replica_info",
+			Help: "Information for availability group replica of SQL DB.",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectID",
+			"availabilityGroup",
+			"instance",
+			"host",
+			"role",
+			"state",
+			"preferred",
+		},
+	)
+	// SQL DB state
+	rubrikMssqlDbInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_mssql_db_info",
+			Help: "Information for SQL DB.",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectID",
+			"instance",
+			"host",
+			"availabilityGroup",
+			"recoveryModel",
+			"state",
+		},
+	)
+	rubrikMssqlDbLiveMounted = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_mssql_db_live_mounted",
+			Help: "Whether SQL DB is a Rubrik live mount (1 is live mounted, 0 is not).",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectID",
+			"instance",
+			"host",
+		},
+	)
+	rubrikMssqlDbRestoring = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_mssql_db_restoring",
+			Help: "Whether SQL DB is being restored (1 is restoring, 0 is not).",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectID",
+			"instance",
+			"host",
+		},
+	)
+)
+
+func init() {
+	// SQL Server host and instance state
+	prometheus.MustRegister(rubrikMssqlHostStatus)
+	prometheus.MustRegister(rubrikMssqlInstanceStatus)
+	// SQL Server availability group state
+	prometheus.MustRegister(rubrikMssqlAvailabilityGroupInfo)
+	prometheus.MustRegister(rubrikMssqlDbReplicaInfo)
+	// SQL DB state
+	prometheus.MustRegister(rubrikMssqlDbInfo)
+	prometheus.MustRegister(rubrikMssqlDbLiveMounted)
+	prometheus.MustRegister(rubrikMssqlDbRestoring)
+}
+
+// GetMssqlStateStats ...
+func GetMssqlStateStats(rubrik *rubrikcdm.Credentials, clusterName string) {
+	hosts, err := getHosts(rubrik)
+	if err != nil {
+		log.Println("Error from stats.GetMssqlStateStats: ", err)
+		return
+	}
+	instances, err := getObjects(rubrik, "v1", "/mssql/instance")
+	if err != nil {
+		log.Println("Error from stats.GetMssqlStateStats: ", err)
+		return
+	}
+	availabilityGroups, err := getObjects(rubrik, "internal", "/mssql/availability_group")
+	if err != nil {
+		log.Println("Error from stats.GetMssqlStateStats: ", err)
+		return
+	}
+	databases, err := getObjects(rubrik, "v1", "/mssql/db?is_relic=false")
+	if err != nil {
+		log.Println("Error from stats.GetMssqlStateStats: ", err)
+		return
+	}
+	util.ResetGauges(
+		rubrikMssqlHostStatus,
+		rubrikMssqlInstanceStatus,
+		rubrikMssqlAvailabilityGroupInfo,
+		rubrikMssqlDbReplicaInfo,
+		rubrikMssqlDbInfo,
+		rubrikMssqlDbLiveMounted,
+		rubrikMssqlDbRestoring)
+	instanceHosts := mssqlInstanceHosts(instances)
+	for _, thisInstance := range instances {
+		rootProperties, _ := thisInstance["rootProperties"].(map[string]interface{})
+		hostID := util.ObjectString(rootProperties, "rootId")
+		thisHost, ok := hosts[hostID]
+		if !ok { // instances of a Windows cluster have no single host
+			continue
+		}
+		rubrikMssqlHostStatus.WithLabelValues(
+			clusterName,
+			thisHost.name,
+			hostID,
+			thisHost.status).Set(thisHost.connected())
+		rubrikMssqlInstanceStatus.WithLabelValues(
+			clusterName,
+			util.ObjectString(thisInstance, "name"),
+			util.ObjectString(thisInstance, "id"),
+			thisHost.name).Set(thisHost.connected())
+	}
+	availabilityGroupNames := map[string]string{}
+	for _, thisGroup := range availabilityGroups {
+		availabilityGroupNames[util.ObjectString(thisGroup, "id")] = util.ObjectString(thisGroup, "name")
+		rubrikMssqlAvailabilityGroupInfo.WithLabelValues(
+			clusterName,
+			util.ObjectString(thisGroup, "name"),
+			util.ObjectString(thisGroup, "id"),
+			util.ObjectString(thisGroup, "backupPreference", "automatedBackupPreference")).Set(1)
+	}
+	for _, thisDb := range databases {
+		objectName := util.ObjectString(thisDb, "name")
+		objectID := util.ObjectString(thisDb, "id")
+		instance, host := mssqlDbInstanceHost(thisDb, instanceHosts)
+		state := util.ObjectString(thisDb, "state")
+		availabilityGroup := "null"
+		if availabilityGroupID, ok := thisDb["availabilityGroupId"].(string); ok && availabilityGroupID != "" {
+			availabilityGroup = availabilityGroupNames[availabilityGroupID]
+			if availabilityGroup == "" {
+				availabilityGroup = availabilityGroupID
+			}
+		}
+		preferredReplicaID := util.ObjectString(thisDb, "preferredReplicaId", "preferredInstanceId")
+		replicas, _ := thisDb["replicas"].([]interface{})
+		for _, w := range replicas {
+			thisReplica, _ := w.(map[string]interface{})
+			availabilityInfo, _ := thisReplica["availabilityInfo"].(map[string]interface{})
+			role := util.ObjectString(availabilityInfo, "role")
+			replicaInstanceID := util.ObjectString(thisReplica, "instanceId")
+			replicaState := util.ObjectString(thisReplica, "state")
+			if state == "null" || role == "PRIMARY" {
+				state = replicaState
+			}
+			if availabilityGroup == "null" {
+				continue
+			}
+			replicaHost := instanceHosts[replicaInstanceID]
+			if replicaHost == "" {
+				replicaHost = "null"
+			}
+			preferred := "false"
+			if replicaInstanceID == preferredReplicaID {
+				preferred = "true"
+			}
+			rubrikMssqlDbReplicaInfo.WithLabelValues(
+				clusterName,
+				objectName,
+				objectID,
+				availabilityGroup,
+				util.ObjectString(thisReplica, "instanceName"),
+				replicaHost,
+				role,
+				replicaState,
+				preferred).Set(1)
+		}
+		rubrikMssqlDbInfo.WithLabelValues(
+			clusterName,
+			objectName,
+			objectID,
+			instance,
+			host,
+			availabilityGroup,
+			util.ObjectString(thisDb, "recoveryModel"),
+			state).Set(1)
+		liveMounted := 0.0
+		if isLiveMount, _ := thisDb["isLiveMount"].(bool); isLiveMount {
+			liveMounted = 1
+		}
+		rubrikMssqlDbLiveMounted.WithLabelValues(
+			clusterName,
+			objectName,
+			objectID,
+			instance,
+			host).Set(liveMounted)
+		restoring := 0.0
+		if state == "RESTORING" {
+			restoring = 1
+		}
+		rubrikMssqlDbRestoring.WithLabelValues(
+			clusterName,
+			objectName,
+			objectID,
+			instance,
+			host).Set(restoring)
+	}
+}
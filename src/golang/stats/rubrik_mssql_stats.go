@@ -3,6 +3,7 @@ package stats
 import (
 	"log"
 	"strconv"
+	"github.com/rubrikinc/rubrik-client-for-prometheus/src/golang/util"
 	"github.com/rubrikinc/rubrik-sdk-for-go/rubrikcdm"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -19,6 +20,8 @@ var (
 			"objectName",
 			"objectID",
 			"location",
+			"instance",
+			"host",
 		},
 	)
 	rubrikMssqlDbCapacityArchiveUsed = prometheus.NewGaugeVec(
@@ -31,6 +34,8 @@ var (
 			"objectName",
 			"objectID",
 			"location",
+			"instance",
+			"host",
 		},
 	)
 )
@@ -48,6 +53,11 @@ func GetMssqlCapacityStats(rubrik *rubrikcdm.Credentials, clusterName string) {
 		log.Printf("Error from stats.GetMssqlCapacityStats: ",err)
 		return
 	}
+	// without the DB list, the capacity is still reported with null host and instance
+	dbHosts, err := getMssqlDbHosts(rubrik)
+	if err != nil {
+		log.Println("Error from stats.GetMssqlCapacityStats: ", err)
+	}
 	reports := reportData.(map[string]interface{})["data"].([]interface{})
 	reportID := reports[0].(map[string]interface{})["id"]
 	body := map[string]interface{}{
@@ -86,16 +96,24 @@ func GetMssqlCapacityStats(rubrik *rubrikcdm.Credentials, clusterName string) {
 					thisArchiveStorage, _ = strconv.ParseFloat(v.([]interface{})[i].(string),64)
 				}
 			}
+			thisHost, thisInstance := "null", "null"
+			if dbHost, ok := dbHosts[thisObjectID]; ok {
+				thisHost, thisInstance = dbHost.host, dbHost.instance
+			}
 			rubrikMssqlDbCapacityLocalUsed.WithLabelValues(
 				clusterName,
 				thisObjectName,
 				thisObjectID,
-				thisLocation).Set(thisLocalStorage)
+				thisLocation,
+				thisInstance,
+				thisHost).Set(thisLocalStorage)
 			rubrikMssqlDbCapacityArchiveUsed.WithLabelValues(
 				clusterName,
 				thisObjectName,
 				thisObjectID,
-				thisLocation).Set(thisArchiveStorage)
+				thisLocation,
+				thisInstance,
+				thisHost).Set(thisArchiveStorage)
 		}
 		if !hasMore {
 			return
@@ -109,4 +127,65 @@ func GetMssqlCapacityStats(rubrik *rubrikcdm.Credentials, clusterName string) {
 			}
 		}
 	}
-}
\ No newline at end of file
+}
+
+// mssqlDbHost is the SQL Server host and instance of a SQL DB.
+type mssqlDbHost struct {
+	host     string
+	instance string
+}
+
+// getMssqlDbHosts returns the host and instance of every SQL DB by DB ID, following
+// mssqlDbInstanceHost.
+func getMssqlDbHosts(rubrik *rubrikcdm.Credentials) (map[string]mssqlDbHost, error) {
+	instances, err := getObjects(rubrik, "v1", "/mssql/instance")
+	if err != nil {
+		return nil, err
+	}
+	databases, err := getObjects(rubrik, "v1", "/mssql/db?is_relic=false")
+	if err != nil {
+		return nil, err
+	}
+	instanceHosts := mssqlInstanceHosts(instances)
+	dbHosts := map[string]mssqlDbHost{}
+	for _, thisDb := range databases {
+		var dbHost mssqlDbHost
+		dbHost.instance, dbHost.host = mssqlDbInstanceHost(thisDb, instanceHosts)
+		dbHosts[util.ObjectString(thisDb, "id")] = dbHost
+	}
+	return dbHosts, nil
+}
+
+// mssqlInstanceHosts returns the host name of every SQL Server instance by instance ID.
+func mssqlInstanceHosts(instances []map[string]interface{}) map[string]string {
+	instanceHosts := map[string]string{}
+	for _, thisInstance := range instances {
+		rootProperties, _ := thisInstance["rootProperties"].(map[string]interface{})
+		instanceHosts[util.ObjectString(thisInstance, "id")] = util.ObjectString(rootProperties, "rootName")
+	}
+	return instanceHosts
+}
+
+// mssqlDbInstanceHost returns the instance and host of a SQL DB. Databases in an
+// availability group are labelled with their primary replica, or "null" when it is unknown.
+func mssqlDbInstanceHost(thisDb map[string]interface{}, instanceHosts map[string]string) (string, string) {
+	availabilityGroupID, _ := thisDb["availabilityGroupId"].(string)
+	if availabilityGroupID == "" {
+		rootProperties, _ := thisDb["rootProperties"].(map[string]interface{})
+		return util.ObjectString(thisDb, "instanceName"), util.ObjectString(rootProperties, "rootName")
+	}
+	replicas, _ := thisDb["replicas"].([]interface{})
+	for _, v := range replicas {
+		thisReplica, _ := v.(map[string]interface{})
+		availabilityInfo, _ := thisReplica["availabilityInfo"].(map[string]interface{})
+		if util.ObjectString(availabilityInfo, "role") != "PRIMARY" {
+			continue
+		}
+		host, ok := instanceHosts[util.ObjectString(thisReplica, "instanceId")]
+		if !ok {
+			host = "null"
+		}
+		return util.ObjectString(thisReplica, "instanceName"), host
+	}
+	return "null", "null"
+}
@@ -0,0 +1,82 @@
+package stats
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/rubrikinc/rubrik-client-for-prometheus/src/golang/util"
+	"github.com/rubrikinc/rubrik-sdk-for-go/rubrikcdm"
+)
+
+// unprotectedSlaDomainIDs are the effective SLA domain IDs of objects Rubrik does not back up.
+var unprotectedSlaDomainIDs = map[string]bool{
+	"null":           true,
+	"UNPROTECTED":    true,
+	"DO_NOT_PROTECT": true,
+}
+
+// rubrikHost is a host registered with the Rubrik cluster.
+type rubrikHost struct {
+	name   string
+	status string
+}
+
+// connected returns 1 when the Rubrik backup service on the host is connected, 0 otherwise.
+func (h rubrikHost) connected() float64 {
+	if h.status == "Connected" {
+		return 1
+	}
+	return 0
+}
+
+// getObjects returns every object listed by a paged endpoint, which may already have a
+// query string.
+func getObjects(rubrik *rubrikcdm.Credentials, apiVersion, endpoint string) ([]map[string]interface{}, error) {
+	separator := "?"
+	if strings.Contains(endpoint, "?") {
+		separator = "&"
+	}
+	var objects []map[string]interface{}
+	offset := 0
+	for {
+		objectData, err := rubrik.Get(apiVersion, endpoint+separator+"limit=500&offset="+strconv.Itoa(offset), 60)
+		if err != nil {
+			return nil, err
+		}
+		data, _ := objectData.(map[string]interface{})["data"].([]interface{})
+		for _, v := range data {
+			if thisObject, ok := v.(map[string]interface{}); ok {
+				objects = append(objects, thisObject)
+			}
+		}
+		offset += len(data)
+		hasMore, _ := objectData.(map[string]interface{})["hasMore"].(bool)
+		if !hasMore || len(data) == 0 {
+			return objects, nil
+		}
+	}
+}
+
+// getHosts returns the hosts registered with the Rubrik cluster by host ID.
+func getHosts(rubrik *rubrikcdm.Credentials) (map[string]rubrikHost, error) {
+	hostData, err := getObjects(rubrik, "v1", "/host")
+	if err != nil {
+		return nil, err
+	}
+	hosts := map[string]rubrikHost{}
+	for _, thisHost := range hostData {
+		hosts[util.ObjectString(thisHost, "id")] = rubrikHost{
+			name:   util.ObjectString(thisHost, "hostname"),
+			status: util.ObjectString(thisHost, "status"),
+		}
+	}
+	return hosts, nil
+}
+
+// boolValue returns 1 for true and 0 for false.
+func boolValue(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,306 @@
+package stats
+
+import (
+	"log"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rubrikinc/rubrik-client-for-prometheus/src/golang/util"
+	"github.com/rubrikinc/rubrik-sdk-for-go/rubrikcdm"
+)
+
+var (
+	// Oracle host and RAC state
+	rubrikOracleHostStatus = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_oracle_host_status",
+			Help: "Connectivity of Oracle host or RAC to Rubrik cluster (1 is Connected, 0 is anything else).",
+		},
+		[]string{
+			"clusterName",
+			"host",
+			"hostID",
+			"hostType",
+			"status",
+		},
+	)
+	// Oracle DB state
+	rubrikOracleDbInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_oracle_db_info",
+			Help: "Information for Oracle DB.",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectID",
+			"location",
+			"sid",
+			"dbType",
+			"archiveLogMode",
+		},
+	)
+	rubrikOracleDbHostStatus = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_oracle_db_host_status",
+			Help: "Connectivity of the host or RAC of Oracle DB to Rubrik cluster (1 is Connected, 0 is anything else).",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectID",
+			"location",
+		},
+	)
+	rubrikOracleDbChannels = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_oracle_db_channels",
+			Help: "Number of RMAN channels configured for Oracle DB backups.",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectID",
+			"location",
+		},
+	)
+	// Oracle DB archive log backup stats
+	rubrikOracleDbLastLogBackup = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_oracle_db_last_log_backup_timestamp_seconds",
+			Help: "Time of the latest archive log backup of Oracle DB.",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectID",
+			"location",
+		},
+	)
+	rubrikOracleDbLogBackupFrequency = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_oracle_db_log_backup_frequency_seconds",
+			Help: "Configured archive log backup frequency of Oracle DB.",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectID",
+			"location",
+		},
+	)
+	rubrikOracleDbLogBackupLag = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_oracle_db_log_backup_lag_seconds",
+			Help: "Time since the latest archive log backup of Oracle DB.",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectID",
+			"location",
+		},
+	)
+	rubrikOracleDbLogBackupLagRatio = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_oracle_db_log_backup_lag_ratio",
+			Help: "Time since the latest archive log backup of Oracle DB divided by its log backup frequency.",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectID",
+			"location",
+		},
+	)
+)
+
+func init() {
+	// Oracle host and RAC state
+	prometheus.MustRegister(rubrikOracleHostStatus)
+	// Oracle DB state
+	prometheus.MustRegister(rubrikOracleDbInfo)
+	prometheus.MustRegister(rubrikOracleDbHostStatus)
+	prometheus.MustRegister(rubrikOracleDbChannels)
+	// Oracle DB archive log backup stats
+	prometheus.MustRegister(rubrikOracleDbLastLogBackup)
+	prometheus.MustRegister(rubrikOracleDbLogBackupFrequency)
+	prometheus.MustRegister(rubrikOracleDbLogBackupLag)
+	prometheus.MustRegister(rubrikOracleDbLogBackupLagRatio)
+}
+
+// oracleDb is the state of one Oracle DB.
+type oracleDb struct {
+	objectName     string
+	objectID       string
+	location       string
+	sid            string
+	dbType         string
+	archiveLogMode string
+	host           rubrikHost
+	hasHost        bool
+	channels       float64
+	frequency      float64
+	lastLogBackup  time.Time
+}
+
+// oracleDbDetails caches the last Oracle DB state read from the details of each DB by
+// DB ID, for DBs whose details cannot be read in a collection.
+var oracleDbDetails = map[string]oracleDb{}
+
+// GetOracleDbStats ...
+func GetOracleDbStats(rubrik *rubrikcdm.Credentials, clusterName string) {
+	hosts, err := getHosts(rubrik)
+	if err != nil {
+		log.Println("Error from stats.GetOracleDbStats: ", err)
+		return
+	}
+	racData, err := getObjects(rubrik, "internal", "/oracle/rac")
+	if err != nil {
+		log.Println("Error from stats.GetOracleDbStats: ", err)
+		return
+	}
+	racs := map[string]rubrikHost{}
+	for _, thisRac := range racData {
+		racs[util.ObjectString(thisRac, "id")] = rubrikHost{
+			name:   util.ObjectString(thisRac, "name"),
+			status: util.ObjectString(thisRac, "status", "connectionStatus"),
+		}
+	}
+	dbData, err := getObjects(rubrik, "internal", "/oracle/db?is_relic=false&is_live_mount=false")
+	if err != nil {
+		log.Println("Error from stats.GetOracleDbStats: ", err)
+		return
+	}
+	var databases []oracleDb
+	usedHosts := map[string]bool{}
+	usedRacs := map[string]bool{}
+	listedDbs := map[string]bool{}
+	for _, thisDb := range dbData {
+		db := oracleDb{
+			objectName:     util.ObjectString(thisDb, "name"),
+			objectID:       util.ObjectString(thisDb, "id"),
+			sid:            util.ObjectString(thisDb, "sid"),
+			archiveLogMode: "null",
+		}
+		listedDbs[db.objectID] = true
+		if racID, ok := thisDb["racId"].(string); ok && racID != "" {
+			db.dbType = "RAC"
+			db.location = util.ObjectString(thisDb, "racName")
+			db.host, db.hasHost = racs[racID]
+			usedRacs[racID] = true
+		} else {
+			hostID := util.ObjectString(thisDb, "standaloneHostId")
+			db.dbType = "Standalone"
+			db.location = util.ObjectString(thisDb, "standaloneHostName")
+			db.host, db.hasHost = hosts[hostID]
+			usedHosts[hostID] = true
+		}
+		// the number of channels, log backup frequency and latest recovery point are only in
+		// the DB details, so a DB whose details cannot be read keeps its last known state
+		dbDetail, err := rubrik.Get("internal", "/oracle/db/"+db.objectID, 60)
+		if err != nil {
+			log.Println("Error from stats.GetOracleDbStats: ", err)
+			if cached, ok := oracleDbDetails[db.objectID]; ok {
+				db.archiveLogMode = cached.archiveLogMode
+				db.channels = cached.channels
+				db.frequency = cached.frequency
+				db.lastLogBackup = cached.lastLogBackup
+			}
+			databases = append(databases, db)
+			continue
+		}
+		thisDetail, _ := dbDetail.(map[string]interface{})
+		if archiveLogMode, ok := thisDetail["isArchiveLogModeEnabled"].(bool); ok {
+			db.archiveLogMode = "NOARCHIVELOG"
+			if archiveLogMode {
+				db.archiveLogMode = "ARCHIVELOG"
+			}
+		}
+		db.channels, _ = thisDetail["numChannels"].(float64)
+		if frequency, ok := thisDetail["logBackupFrequencyInMinutes"].(float64); ok {
+			db.frequency = frequency * 60
+		}
+		if db.archiveLogMode != "NOARCHIVELOG" {
+			db.lastLogBackup = util.ParseReportTime(util.ObjectString(thisDetail, "latestRecoveryPoint"))
+		}
+		oracleDbDetails[db.objectID] = db
+		databases = append(databases, db)
+	}
+	for dbID := range oracleDbDetails {
+		if !listedDbs[dbID] {
+			delete(oracleDbDetails, dbID)
+		}
+	}
+	util.ResetGauges(
+		rubrikOracleHostStatus,
+		rubrikOracleDbInfo,
+		rubrikOracleDbHostStatus,
+		rubrikOracleDbChannels,
+		rubrikOracleDbLastLogBackup,
+		rubrikOracleDbLogBackupFrequency,
+		rubrikOracleDbLogBackupLag,
+		rubrikOracleDbLogBackupLagRatio)
+	for hostID := range usedHosts {
+		if thisHost, ok := hosts[hostID]; ok {
+			rubrikOracleHostStatus.WithLabelValues(clusterName, thisHost.name, hostID, "Standalone", thisHost.status).Set(thisHost.connected())
+		}
+	}
+	for racID := range usedRacs {
+		if thisRac, ok := racs[racID]; ok {
+			rubrikOracleHostStatus.WithLabelValues(clusterName, thisRac.name, racID, "RAC", thisRac.status).Set(thisRac.connected())
+		}
+	}
+	for _, db := range databases {
+		rubrikOracleDbInfo.WithLabelValues(
+			clusterName,
+			db.objectName,
+			db.objectID,
+			db.location,
+			db.sid,
+			db.dbType,
+			db.archiveLogMode).Set(1)
+		if db.hasHost {
+			rubrikOracleDbHostStatus.WithLabelValues(
+				clusterName,
+				db.objectName,
+				db.objectID,
+				db.location).Set(db.host.connected())
+		}
+		if db.channels > 0 {
+			rubrikOracleDbChannels.WithLabelValues(
+				clusterName,
+				db.objectName,
+				db.objectID,
+				db.location).Set(db.channels)
+		}
+		if db.frequency > 0 {
+			rubrikOracleDbLogBackupFrequency.WithLabelValues(
+				clusterName,
+				db.objectName,
+				db.objectID,
+				db.location).Set(db.frequency)
+		}
+		if db.lastLogBackup.IsZero() {
+			continue
+		}
+		lag := time.Since(db.lastLogBackup).Seconds()
+		rubrikOracleDbLastLogBackup.WithLabelValues(
+			clusterName,
+			db.objectName,
+			db.objectID,
+			db.location).Set(float64(db.lastLogBackup.Unix()))
+		rubrikOracleDbLogBackupLag.WithLabelValues(
+			clusterName,
+			db.objectName,
+			db.objectID,
+			db.location).Set(lag)
+		if db.frequency > 0 {
+			rubrikOracleDbLogBackupLagRatio.WithLabelValues(
+				clusterName,
+				db.objectName,
+				db.objectID,
+				db.location).Set(lag / db.frequency)
+		}
+	}
+}
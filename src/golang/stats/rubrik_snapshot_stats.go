@@ -0,0 +1,231 @@
+package stats
+
+import (
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rubrikinc/rubrik-client-for-prometheus/src/golang/util"
+	"github.com/rubrikinc/rubrik-sdk-for-go/rubrikcdm"
+)
+
+var (
+	// snapshot inventory stats
+	rubrikObjectLocalSnapshots = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_object_local_snapshots",
+			Help: "Number of local snapshots of object in Rubrik cluster.",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectType",
+			"objectID",
+			"location",
+		},
+	)
+	rubrikObjectArchivedSnapshots = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_object_archived_snapshots",
+			Help: "Number of archived snapshots of object in Rubrik cluster.",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectType",
+			"objectID",
+			"location",
+		},
+	)
+	rubrikObjectReplicatedSnapshots = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_object_replicated_snapshots",
+			Help: "Number of replicated snapshots of object in Rubrik cluster.",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectType",
+			"objectID",
+			"location",
+		},
+	)
+	rubrikObjectOnDemandSnapshots = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_object_on_demand_snapshots",
+			Help: "Number of on demand snapshots of object in Rubrik cluster.",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectType",
+			"objectID",
+			"location",
+		},
+	)
+	rubrikObjectOldestSnapshot = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_object_oldest_snapshot_timestamp_seconds",
+			Help: "Time of the oldest snapshot of object in Rubrik cluster.",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectType",
+			"objectID",
+			"location",
+		},
+	)
+	rubrikObjectNewestSnapshot = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_object_newest_snapshot_timestamp_seconds",
+			Help: "Time of the newest snapshot of object in Rubrik cluster.",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectType",
+			"objectID",
+			"location",
+		},
+	)
+)
+
+func init() {
+	// snapshot inventory stats
+	prometheus.MustRegister(rubrikObjectLocalSnapshots)
+	prometheus.MustRegister(rubrikObjectArchivedSnapshots)
+	prometheus.MustRegister(rubrikObjectReplicatedSnapshots)
+	prometheus.MustRegister(rubrikObjectOnDemandSnapshots)
+	prometheus.MustRegister(rubrikObjectOldestSnapshot)
+	prometheus.MustRegister(rubrikObjectNewestSnapshot)
+}
+
+// snapshotInventory is one row of the object protection summary report table.
+type snapshotInventory struct {
+	objectName          string
+	objectType          string
+	objectID            string
+	location            string
+	localSnapshots      float64
+	archivedSnapshots   float64
+	replicatedSnapshots float64
+	onDemandSnapshots   float64
+	oldestSnapshot      time.Time
+	newestSnapshot      time.Time
+}
+
+// GetSnapshotInventoryStats ...
+func GetSnapshotInventoryStats(rubrik *rubrikcdm.Credentials, clusterName string) {
+	reportData, err := rubrik.Get("internal", "/report?report_template=ObjectProtectionSummary&report_type=Canned", 60) // get our object protection summary report
+	if err != nil {
+		log.Println("Error from stats.GetSnapshotInventoryStats: ", err)
+		return
+	}
+	reports := reportData.(map[string]interface{})["data"].([]interface{})
+	reportID := reports[0].(map[string]interface{})["id"]
+	var objects []snapshotInventory
+	body := map[string]interface{}{
+		"limit": 100,
+	}
+	for {
+		tableData, err := rubrik.Post("internal", "/report/"+reportID.(string)+"/table", body, 60) // get our first page of data for the report
+		if err != nil {
+			log.Println("Error from stats.GetSnapshotInventoryStats: ", err)
+			return
+		}
+		dataGrid := tableData.(map[string]interface{})["dataGrid"].([]interface{})
+		hasMore := tableData.(map[string]interface{})["hasMore"].(bool)
+		cursor := tableData.(map[string]interface{})["cursor"]
+		columns := tableData.(map[string]interface{})["columns"].([]interface{})
+		for _, v := range dataGrid {
+			thisObject := snapshotInventory{objectName: "null", objectType: "null", objectID: "null", location: "null"}
+			for i := 0; i < len(columns); i++ {
+				value, ok := v.([]interface{})[i].(string)
+				if !ok {
+					continue
+				}
+				switch columns[i] {
+				case "ObjectId", "ObjectLinkingId":
+					thisObject.objectID = value
+				case "ObjectName":
+					thisObject.objectName = value
+				case "ObjectType":
+					thisObject.objectType = value
+				case "Location":
+					thisObject.location = value
+				case "LocalSnapshots":
+					thisObject.localSnapshots, _ = strconv.ParseFloat(value, 64)
+				case "ArchiveSnapshots", "ArchivedSnapshots":
+					thisObject.archivedSnapshots, _ = strconv.ParseFloat(value, 64)
+				case "ReplicaSnapshots", "ReplicatedSnapshots":
+					thisObject.replicatedSnapshots, _ = strconv.ParseFloat(value, 64)
+				case "LocalOnDemandSnapshots", "OnDemandSnapshots":
+					thisObject.onDemandSnapshots, _ = strconv.ParseFloat(value, 64)
+				case "OldestLocalSnapshot", "OldestSnapshot":
+					thisObject.oldestSnapshot = util.ParseReportTime(value)
+				case "LatestLocalSnapshot", "LastSnapshot":
+					thisObject.newestSnapshot = util.ParseReportTime(value)
+				}
+			}
+			objects = append(objects, thisObject)
+		}
+		if !hasMore {
+			break
+		}
+		body = map[string]interface{}{
+			"limit":  1000,
+			"cursor": cursor,
+		}
+	}
+	util.ResetGauges(
+		rubrikObjectLocalSnapshots,
+		rubrikObjectArchivedSnapshots,
+		rubrikObjectReplicatedSnapshots,
+		rubrikObjectOnDemandSnapshots,
+		rubrikObjectOldestSnapshot,
+		rubrikObjectNewestSnapshot)
+	for _, o := range objects {
+		rubrikObjectLocalSnapshots.WithLabelValues(
+			clusterName,
+			o.objectName,
+			o.objectType,
+			o.objectID,
+			o.location).Set(o.localSnapshots)
+		rubrikObjectArchivedSnapshots.WithLabelValues(
+			clusterName,
+			o.objectName,
+			o.objectType,
+			o.objectID,
+			o.location).Set(o.archivedSnapshots)
+		rubrikObjectReplicatedSnapshots.WithLabelValues(
+			clusterName,
+			o.objectName,
+			o.objectType,
+			o.objectID,
+			o.location).Set(o.replicatedSnapshots)
+		rubrikObjectOnDemandSnapshots.WithLabelValues(
+			clusterName,
+			o.objectName,
+			o.objectType,
+			o.objectID,
+			o.location).Set(o.onDemandSnapshots)
+		if !o.oldestSnapshot.IsZero() {
+			rubrikObjectOldestSnapshot.WithLabelValues(
+				clusterName,
+				o.objectName,
+				o.objectType,
+				o.objectID,
+				o.location).Set(float64(o.oldestSnapshot.Unix()))
+		}
+		if !o.newestSnapshot.IsZero() {
+			rubrikObjectNewestSnapshot.WithLabelValues(
+				clusterName,
+				o.objectName,
+				o.objectType,
+				o.objectID,
+				o.location).Set(float64(o.newestSnapshot.Unix()))
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package stats
+
+import (
+	"encoding/json"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rubrikinc/rubrik-client-for-prometheus/src/golang/util"
+	"github.com/rubrikinc/rubrik-sdk-for-go/rubrikcdm"
+)
+
+// maxVmDetailRequests is how many VM details GetVSphereHealthStats requests per
+// collection. The guest credential status of the other VMs is kept from earlier
+// collections, and the oldest are refreshed first.
+const maxVmDetailRequests = 100
+
+// vmBackupEventWindow is how far back GetVSphereHealthStats reads the backup events of
+// VMware vSphere VMs for the problems in vmBackupIssues.
+const vmBackupEventWindow = 24 * time.Hour
+
+// vmBackupIssue is a vSphere problem the cluster reports in the message of VM backup
+// events, which none of the VMware APIs expose as a field.
+type vmBackupIssue struct {
+	gauge    *prometheus.GaugeVec
+	keywords []string // any of these in the lower case event message
+}
+
+var (
+	// VMware vCenter health
+	rubrikVSphereVcenterStatus = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_vsphere_vcenter_status",
+			Help: "Connectivity of vCenter to Rubrik cluster (1 is Connected, 0 is anything else).",
+		},
+		[]string{
+			"clusterName",
+			"vcenter",
+			"vcenterID",
+			"status",
+		},
+	)
+	rubrikVSphereVcenterLastRefresh = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_vsphere_vcenter_last_refresh_timestamp_seconds",
+			Help: "Time of the last refresh of vCenter inventory by Rubrik cluster.",
+		},
+		[]string{
+			"clusterName",
+			"vcenter",
+			"vcenterID",
+		},
+	)
+	// VMware vSphere VM health
+	rubrikVSphereVmToolsInstalled = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_vsphere_vm_tools_installed",
+			Help: "Whether VMware Tools is installed in VMware vSphere VM (1 is installed, 0 is not).",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectID",
+			"location",
+		},
+	)
+	rubrikVSphereVmGuestCredentialValid = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_vsphere_vm_guest_credential_valid",
+			Help: "Whether the guest OS credentials of VMware vSphere VM are accepted (1 is valid, 0 is anything else).",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectID",
+			"location",
+			"status",
+		},
+	)
+	rubrikVSphereVmEsxiHostUnreachable = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_vsphere_vm_esxi_host_unreachable",
+			Help: "Whether a backup of VMware vSphere VM in the last 24 hours reported that its ESXi host could not be reached (1 is reported, 0 is not).",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectID",
+			"location",
+		},
+	)
+	rubrikVSphereVmCbtDisabled = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_vsphere_vm_cbt_disabled",
+			Help: "Whether a backup of VMware vSphere VM in the last 24 hours reported that changed block tracking is disabled (1 is reported, 0 is not).",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectID",
+			"location",
+		},
+	)
+	rubrikVSphereVmConsolidationNeeded = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rubrik_vsphere_vm_consolidation_needed",
+			Help: "Whether a backup of VMware vSphere VM in the last 24 hours reported that its snapshots need consolidation (1 is reported, 0 is not).",
+		},
+		[]string{
+			"clusterName",
+			"objectName",
+			"objectID",
+			"location",
+		},
+	)
+)
+
+// vmBackupIssues are the problems read from the backup events of VMware vSphere VMs.
+var vmBackupIssues = []vmBackupIssue{
+	{rubrikVSphereVmEsxiHostUnreachable, []string{"unable to connect to esxi", "esxi host is not reachable", "esxi host unreachable", "failed to connect to esxi"}},
+	{rubrikVSphereVmCbtDisabled, []string{"changed block tracking is disabled", "cbt is disabled", "cbt disabled"}},
+	{rubrikVSphereVmConsolidationNeeded, []string{"consolidation is needed", "needs consolidation", "consolidation needed", "snapshot consolidation"}},
+}
+
+func init() {
+	// VMware vCenter health
+	prometheus.MustRegister(rubrikVSphereVcenterStatus)
+	prometheus.MustRegister(rubrikVSphereVcenterLastRefresh)
+	// VMware vSphere VM health
+	prometheus.MustRegister(rubrikVSphereVmToolsInstalled)
+	prometheus.MustRegister(rubrikVSphereVmGuestCredentialValid)
+	prometheus.MustRegister(rubrikVSphereVmEsxiHostUnreachable)
+	prometheus.MustRegister(rubrikVSphereVmCbtDisabled)
+	prometheus.MustRegister(rubrikVSphereVmConsolidationNeeded)
+}
+
+// vmGuestCredential is the guest credential status of a VM, from its details.
+type vmGuestCredential struct {
+	status  string
+	fetched time.Time
+}
+
+// vmGuestCredentials caches the guest credential status of VMs by VM ID.
+var vmGuestCredentials = map[string]vmGuestCredential{}
+
+// GetVSphereHealthStats ...
+func GetVSphereHealthStats(rubrik *rubrikcdm.Credentials, clusterName string) {
+	vcenters, err := getObjects(rubrik, "v1", "/vmware/vcenter")
+	if err != nil {
+		log.Println("Error from stats.GetVSphereHealthStats: ", err)
+		return
+	}
+	vms, err := getObjects(rubrik, "v1", "/vmware/vm?is_relic=false")
+	if err != nil {
+		log.Println("Error from stats.GetVSphereHealthStats: ", err)
+		return
+	}
+	// without the backup events the other VM health is still reported, but not the
+	// vmBackupIssues, since the VMs could not be told apart from those without problems
+	vmIssues, err := getVmBackupIssues(rubrik)
+	if err != nil {
+		log.Println("Error from stats.GetVSphereHealthStats: ", err)
+	}
+	// the guest credential status is only in the VM details, so refresh the protected VMs
+	// with the oldest status, up to maxVmDetailRequests per collection
+	var protectedVMs []string
+	listedVMs := map[string]bool{}
+	for _, thisVM := range vms {
+		vmID := util.ObjectString(thisVM, "id")
+		listedVMs[vmID] = true
+		if !unprotectedSlaDomainIDs[util.ObjectString(thisVM, "effectiveSlaDomainId")] {
+			protectedVMs = append(protectedVMs, vmID)
+		}
+	}
+	for vmID := range vmGuestCredentials {
+		if !listedVMs[vmID] {
+			delete(vmGuestCredentials, vmID)
+		}
+	}
+	sort.SliceStable(protectedVMs, func(i, j int) bool {
+		return vmGuestCredentials[protectedVMs[i]].fetched.Before(vmGuestCredentials[protectedVMs[j]].fetched)
+	})
+	if len(protectedVMs) > maxVmDetailRequests {
+		protectedVMs = protectedVMs[:maxVmDetailRequests]
+	}
+	for _, vmID := range protectedVMs {
+		vmDetail, err := rubrik.Get("v1", "/vmware/vm/"+vmID, 60)
+		if err != nil {
+			log.Println("Error from stats.GetVSphereHealthStats: ", err)
+			continue
+		}
+		thisVM, _ := vmDetail.(map[string]interface{})
+		vmGuestCredentials[vmID] = vmGuestCredential{
+			status:  util.ObjectString(thisVM, "guestCredentialAuthorizationStatus"),
+			fetched: time.Now(),
+		}
+	}
+	util.ResetGauges(
+		rubrikVSphereVcenterStatus,
+		rubrikVSphereVcenterLastRefresh,
+		rubrikVSphereVmToolsInstalled,
+		rubrikVSphereVmGuestCredentialValid,
+		rubrikVSphereVmEsxiHostUnreachable,
+		rubrikVSphereVmCbtDisabled,
+		rubrikVSphereVmConsolidationNeeded)
+	for _, thisVcenter := range vcenters {
+		vcenterName := util.ObjectString(thisVcenter, "name")
+		vcenterID := util.ObjectString(thisVcenter, "id")
+		connectionStatus, _ := thisVcenter["connectionStatus"].(map[string]interface{})
+		status := util.ObjectString(connectionStatus, "status")
+		rubrikVSphereVcenterStatus.WithLabelValues(
+			clusterName,
+			vcenterName,
+			vcenterID,
+			status).Set(boolValue(status == "Connected"))
+		lastRefresh := util.ParseReportTime(util.ObjectString(thisVcenter, "lastRefreshTime"))
+		if !lastRefresh.IsZero() {
+			rubrikVSphereVcenterLastRefresh.WithLabelValues(
+				clusterName,
+				vcenterName,
+				vcenterID).Set(float64(lastRefresh.Unix()))
+		}
+	}
+	for _, thisVM := range vms {
+		objectName := util.ObjectString(thisVM, "name")
+		objectID := util.ObjectString(thisVM, "id")
+		location := util.ObjectString(thisVM, "hostName")
+		if toolsInstalled, ok := thisVM["vmwareToolsInstalled"].(bool); ok {
+			rubrikVSphereVmToolsInstalled.WithLabelValues(
+				clusterName,
+				objectName,
+				objectID,
+				location).Set(boolValue(toolsInstalled))
+		}
+		if credential, ok := vmGuestCredentials[objectID]; ok && credential.status != "null" {
+			rubrikVSphereVmGuestCredentialValid.WithLabelValues(
+				clusterName,
+				objectName,
+				objectID,
+				location,
+				credential.status).Set(boolValue(credential.status == "SUCCESSFUL"))
+		}
+		for _, issue := range vmBackupIssues {
+			if vmIssues == nil {
+				break
+			}
+			issue.gauge.WithLabelValues(
+				clusterName,
+				objectName,
+				objectID,
+				location).Set(boolValue(vmIssues[objectID][issue.gauge]))
+		}
+	}
+}
+
+// getVmBackupIssues returns, by VM ID, the vmBackupIssues reported by the latest event
+// of each VM backup in the last vmBackupEventWindow.
+func getVmBackupIssues(rubrik *rubrikcdm.Credentials) (map[string]map[*prometheus.GaugeVec]bool, error) {
+	query := url.Values{}
+	query.Set("limit", "9999")
+	query.Set("event_type", "Backup")
+	query.Set("object_type", "VmwareVm")
+	query.Set("after_date", time.Now().Add(-vmBackupEventWindow).UTC().Format("2006-01-02T15:04:05.000Z"))
+	eventData, err := rubrik.Get("v1", "/event/latest?"+query.Encode(), 60)
+	if err != nil {
+		return nil, err
+	}
+	vmIssues := map[string]map[*prometheus.GaugeVec]bool{}
+	data, _ := eventData.(map[string]interface{})["data"].([]interface{})
+	for _, v := range data {
+		thisEvent, _ := v.(map[string]interface{})
+		latestEvent, ok := thisEvent["latestEvent"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		eventInfo, _ := latestEvent["eventInfo"].(string)
+		var info struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal([]byte(eventInfo), &info) != nil {
+			continue
+		}
+		message := strings.ToLower(info.Message)
+		objectID := util.ObjectString(latestEvent, "objectId")
+		for _, issue := range vmBackupIssues {
+			for _, keyword := range issue.keywords {
+				if !strings.Contains(message, keyword) {
+					continue
+				}
+				if vmIssues[objectID] == nil {
+					vmIssues[objectID] = map[*prometheus.GaugeVec]bool{}
+				}
+				vmIssues[objectID][issue.gauge] = true
+				break
+			}
+		}
+	}
+	return vmIssues, nil
+}
@@ -0,0 +1,55 @@
+package util
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// reportTimeLayouts are the timestamp formats used in report tables and API responses.
+var reportTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"Jan 2, 2006 3:04:05 PM",
+}
+
+// ParseReportTime parses a report table timestamp, returning the zero time when the
+// value is empty or in an unknown format.
+func ParseReportTime(value string) time.Time {
+	for _, layout := range reportTimeLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed
+		}
+	}
+	return time.Time{}
+}
+
+// ObjectString returns the value of the first of keys holding a non-empty string, or
+// the first string of a non-empty list, and "null" when none of them do.
+func ObjectString(object map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		switch value := object[key].(type) {
+		case string:
+			if value != "" {
+				return value
+			}
+		case []interface{}:
+			if len(value) > 0 {
+				if first, ok := value[0].(string); ok {
+					return first
+				}
+			}
+		}
+	}
+	return "null"
+}
+
+// ResetGauges drops every series of the given gauges. Collectors call it only once
+// everything they report has been read from the cluster, just before setting the new
+// values, so that deleted objects drop out without the gauges going empty on errors.
+func ResetGauges(gauges ...*prometheus.GaugeVec) {
+	for _, gauge := range gauges {
+		gauge.Reset()
+	}
+}